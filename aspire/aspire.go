@@ -0,0 +1,148 @@
+// Package aspire holds the resources needed to start a .NET Aspire dashboard testcontainer, which receives all
+// three signals over OTLP and shows them in a single UI.
+package aspire
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/registry"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// DefaultImage is the image used for the Aspire dashboard container.
+const DefaultImage = "mcr.microsoft.com/dotnet/aspire-dashboard:9.1"
+
+// DefaultAlias is the network alias that other containers use to reach the Aspire dashboard.
+const DefaultAlias = "aspire"
+
+// Aspire hold the testcontainer, ports and network used by the Aspire dashboard. If instantiating yourself,
+// be sure to populate Aspire.Network, otherwise a new network will be generated.
+// The UI is on port 18888, and OTLP is received on ports 18889 (gRPC) and 18890 (HTTP).
+// Host is the hostname that the ports are reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
+// BrowserToken is the token that logs into the UI, and is generated when not set.
+type Aspire struct {
+	Ports        map[int]nat.Port
+	Network      *testcontainers.DockerNetwork
+	Host         string
+	Image        string
+	Registry     *registry.Config
+	Alias        string
+	Name         string
+	BrowserToken string
+}
+
+// Start starts the Aspire dashboard container.
+func (a *Aspire) Start(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+	var err error
+
+	a.Ports = make(map[int]nat.Port)
+
+	if a.Network == nil {
+		a.Network, err = network.New(ctx)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("aspire: network not provided and could not create a new one: %w", err)
+		}
+	}
+
+	if a.Alias == "" {
+		a.Alias = DefaultAlias
+	}
+
+	if a.BrowserToken == "" {
+		token := make([]byte, 16)
+		if _, err := rand.Read(token); err != nil {
+			return emptyFunc, fmt.Errorf("aspire: could not generate a browser token: %w", err)
+		}
+		a.BrowserToken = hex.EncodeToString(token)
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          a.image(),
+		ExposedPorts:   []string{"18888/tcp", "18889/tcp", "18890/tcp"},
+		Networks:       []string{a.Network.Name},
+		NetworkAliases: map[string][]string{a.Network.Name: {a.Alias}},
+		Env: map[string]string{
+			"Dashboard__Frontend__AuthMode":     "BrowserToken",
+			"Dashboard__Frontend__BrowserToken": a.BrowserToken,
+			"Dashboard__Otlp__AuthMode":         "Unsecured",
+		},
+		// the image has no shell for a listening port check, and the UI redirects to the login page
+		WaitingFor: wait.ForHTTP("/").WithPort("18888/tcp").
+			WithStatusCodeMatcher(func(status int) bool { return status < http.StatusInternalServerError }).
+			WithStartupTimeout(time.Minute),
+	}
+	if err := a.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("aspire: could not prepare the image: %w", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return emptyFunc, fmt.Errorf("aspire: could not start the testcontainer: %w", err)
+	}
+
+	a.Name, err = container.Name(ctx)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("aspire: could not read the name of the container from the testcontainer: %w", err)
+	}
+	a.Name = strings.TrimPrefix(a.Name, "/")
+
+	for _, portNum := range []int{18888, 18889, 18890} {
+		a.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
+		if err != nil {
+			return emptyFunc, fmt.Errorf("aspire: could not retrieve port %d from the testcontainer: %w", portNum, err)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
+	}, nil
+}
+
+// OTLPEndpoint returns the host:port that the collector's otlp exporter sends to on the stack network.
+func (a *Aspire) OTLPEndpoint() string {
+	return fmt.Sprintf("%s:18889", a.Alias)
+}
+
+// URL returns the URL of the dashboard's UI.
+func (a *Aspire) URL() string {
+	return a.Endpoint(18888)
+}
+
+// LoginURL returns the URL of the dashboard's UI that logs in with the browser token.
+func (a *Aspire) LoginURL() string {
+	return fmt.Sprintf("%s/login?t=%s", a.URL(), a.BrowserToken)
+}
+
+// Endpoint returns the URL that the given container port of the Aspire dashboard can be reached on.
+func (a *Aspire) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", a.host(), a.Ports[port].Int())
+}
+
+func (a *Aspire) host() string {
+	if a.Host == "" {
+		return "localhost"
+	}
+	return a.Host
+}
+
+func (a *Aspire) image() string {
+	if a.Image == "" {
+		return DefaultImage
+	}
+	return a.Image
+}
@@ -0,0 +1,29 @@
+package aspire
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAspireStart(t *testing.T) {
+	t.Parallel()
+	a := Aspire{}
+	shutdownFunc, err := a.Start(t.Context())
+	require.NoError(t, err, "aspire must be able to start")
+	t.Cleanup(func() {
+		if err := shutdownFunc(context.Background()); err != nil {
+			t.Logf("error shutting down aspire: %v", err)
+		}
+	})
+	assert.Len(t, a.BrowserToken, 32, "a browser token must be generated")
+
+	t.Logf("using endpoint: %s", a.LoginURL())
+
+	resp, err := http.Get(a.LoginURL())
+	require.NoError(t, err, "must be able to call aspire")
+	assert.Equal(t, 200, resp.StatusCode, "request should be 200")
+}
@@ -0,0 +1,131 @@
+// Package backend defines backend-neutral interfaces for querying the telemetry received by the stack, so that
+// tests don't depend on the concrete Jaeger, Seq and Prometheus clients and backends can be swapped or faked.
+package backend
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// TraceBackend returns the traces received by a tracing backend.
+// Traces keeps fetching, for a maximum of maxRetries attempts, until expectedTraces traces that include a span of
+// the service are returned, most recent first.
+type TraceBackend interface {
+	Traces(expectedTraces int, maxRetries int, service string) ([]Trace, error)
+}
+
+// LogBackend returns the log records received by a logging backend.
+// LogRecords keeps fetching, for a maximum of maxRetries attempts, until expectedRecords records are returned,
+// most recent first.
+type LogBackend interface {
+	LogRecords(expectedRecords int, maxRetries int) ([]LogRecord, error)
+}
+
+// MetricBackend returns the metrics received by a metrics backend.
+// Series keeps fetching, for a maximum of maxRetries attempts, until the first series of metricName for the service
+// has expectedPoints points over the last since.
+type MetricBackend interface {
+	Series(expectedPoints int, maxRetries int, metricName string, service string, since time.Duration) (Series, error)
+}
+
+// Trace is a group of spans that share a trace ID.
+type Trace struct {
+	TraceID string
+	Spans   []Span
+}
+
+// Span is a single span, with its IDs encoded as hex.
+// Kind is the lowercase span kind (e.g. server), and Status is OK, ERROR or empty when unset.
+// Attributes and Resource hold the span and resource attributes, keyed by their dotted names.
+type Span struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	Name          string
+	Service       string
+	Kind          string
+	Scope         string
+	StartTime     time.Time
+	Duration      time.Duration
+	Status        string
+	StatusMessage string
+	Attributes    map[string]any
+	Resource      map[string]any
+	Events        []Event
+}
+
+// Event is an event recorded on a span.
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]any
+}
+
+// LogRecord is a single log record, with its trace and span IDs encoded as hex.
+// Level is the severity as reported by the backend. Attributes and Resource hold the record and resource attributes,
+// keyed by their dotted names.
+type LogRecord struct {
+	Time       time.Time
+	Body       string
+	Level      string
+	TraceID    string
+	SpanID     string
+	Attributes map[string]any
+	Resource   map[string]any
+}
+
+// Series is a metric series, named and labelled as the backend stores it.
+type Series struct {
+	Name   string
+	Labels map[string]string
+	Points []Point
+}
+
+// Point is a single sample of a series.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Edge is a dependency between two services, where the client service calls the server service.
+// Requests counts the calls, of which Failed failed. ConnectionType and Failed are only known when the edge is derived
+// by the collector's servicegraph connector, e.g. database for a call to a database.
+type Edge struct {
+	Client         string
+	Server         string
+	ConnectionType string
+	Requests       float64
+	Failed         float64
+}
+
+// SortEdges orders the edges by client and then server.
+func SortEdges(edges []Edge) {
+	slices.SortFunc(edges, func(a Edge, b Edge) int {
+		if c := strings.Compare(a.Client, b.Client); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Server, b.Server)
+	})
+}
+
+// Flatten flattens nested attribute maps into dotted keys, so that {"service": {"name": "api"}} becomes
+// {"service.name": "api"}.
+func Flatten(attributes map[string]any) map[string]any {
+	flat := map[string]any{}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		nested, ok := v.(map[string]any)
+		if !ok || len(nested) == 0 {
+			flat[prefix] = v
+			return
+		}
+		for k, v := range nested {
+			walk(prefix+"."+k, v)
+		}
+	}
+	for k, v := range attributes {
+		walk(k, v)
+	}
+	return flat
+}
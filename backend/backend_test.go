@@ -0,0 +1,33 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+
+	flat := Flatten(map[string]any{
+		"service": map[string]any{"name": "api", "namespace": map[string]any{"id": "a"}},
+		"empty":   map[string]any{},
+		"user":    "u-1",
+	})
+
+	assert.Equal(t, map[string]any{
+		"service.name":         "api",
+		"service.namespace.id": "a",
+		"empty":                map[string]any{},
+		"user":                 "u-1",
+	}, flat)
+}
+
+func TestSortEdges(t *testing.T) {
+	t.Parallel()
+
+	edges := []Edge{{Client: "users", Server: "postgres"}, {Client: "frontend", Server: "users"}, {Client: "frontend", Server: "auth"}}
+	SortEdges(edges)
+
+	assert.Equal(t, []Edge{{Client: "frontend", Server: "auth"}, {Client: "frontend", Server: "users"}, {Client: "users", Server: "postgres"}}, edges)
+}
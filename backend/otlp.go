@@ -0,0 +1,104 @@
+package backend
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// TracesFromOTLP groups OTLP spans into traces by their trace ID, in the order that each trace is first seen,
+// for backends that return traces as OTLP.
+func TracesFromOTLP(resourceSpans []*tracepb.ResourceSpans) []Trace {
+	var traces []Trace
+	index := map[string]int{}
+
+	for _, rs := range resourceSpans {
+		resource := AttributesFromOTLP(rs.GetResource().GetAttributes())
+		service, _ := resource["service.name"].(string)
+
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				span := Span{
+					TraceID:    hex.EncodeToString(s.GetTraceId()),
+					SpanID:     hex.EncodeToString(s.GetSpanId()),
+					Name:       s.GetName(),
+					Service:    service,
+					Scope:      ss.GetScope().GetName(),
+					StartTime:  time.Unix(0, int64(s.GetStartTimeUnixNano())),
+					Duration:   time.Duration(s.GetEndTimeUnixNano() - s.GetStartTimeUnixNano()),
+					Attributes: AttributesFromOTLP(s.GetAttributes()),
+					Resource:   resource,
+				}
+				if len(s.GetParentSpanId()) > 0 {
+					span.ParentSpanID = hex.EncodeToString(s.GetParentSpanId())
+				}
+				if s.GetKind() != tracepb.Span_SPAN_KIND_UNSPECIFIED {
+					span.Kind = strings.ToLower(strings.TrimPrefix(s.GetKind().String(), "SPAN_KIND_"))
+				}
+				switch s.GetStatus().GetCode() {
+				case tracepb.Status_STATUS_CODE_OK:
+					span.Status = "OK"
+				case tracepb.Status_STATUS_CODE_ERROR:
+					span.Status = "ERROR"
+				}
+				span.StatusMessage = s.GetStatus().GetMessage()
+				for _, e := range s.GetEvents() {
+					span.Events = append(span.Events, Event{
+						Name:       e.GetName(),
+						Time:       time.Unix(0, int64(e.GetTimeUnixNano())),
+						Attributes: AttributesFromOTLP(e.GetAttributes()),
+					})
+				}
+
+				i, ok := index[span.TraceID]
+				if !ok {
+					i = len(traces)
+					index[span.TraceID] = i
+					traces = append(traces, Trace{TraceID: span.TraceID})
+				}
+				traces[i].Spans = append(traces[i].Spans, span)
+			}
+		}
+	}
+
+	return traces
+}
+
+// AttributesFromOTLP converts OTLP attributes into a map of their Go values: a string, bool, int64, float64,
+// []byte, []any or map[string]any.
+func AttributesFromOTLP(attributes []*commonpb.KeyValue) map[string]any {
+	values := make(map[string]any, len(attributes))
+	for _, kv := range attributes {
+		values[kv.GetKey()] = ValueFromOTLP(kv.GetValue())
+	}
+	return values
+}
+
+// ValueFromOTLP converts an OTLP attribute value into its Go value, as described on AttributesFromOTLP.
+func ValueFromOTLP(v *commonpb.AnyValue) any {
+	switch v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.GetStringValue()
+	case *commonpb.AnyValue_BoolValue:
+		return v.GetBoolValue()
+	case *commonpb.AnyValue_IntValue:
+		return v.GetIntValue()
+	case *commonpb.AnyValue_DoubleValue:
+		return v.GetDoubleValue()
+	case *commonpb.AnyValue_BytesValue:
+		return v.GetBytesValue()
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, 0, len(v.GetArrayValue().GetValues()))
+		for _, e := range v.GetArrayValue().GetValues() {
+			values = append(values, ValueFromOTLP(e))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		return AttributesFromOTLP(v.GetKvlistValue().GetValues())
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,180 @@
+// Command otelstack runs the otelstack interactively, outside of go tests. It starts the stack,
+// prints the endpoints of each service, writes the environment variables needed to export to
+// (and attach to) the stack to a file, and tears everything down on Ctrl-C.
+//
+// Usage:
+//
+//	otelstack [-metrics=false] [-logs=false] [-traces=false] [-env-file .otelstack.env]
+//	otelstack -compose docker-compose.yaml
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/adreasnow/otelstack"
+	"github.com/adreasnow/otelstack/registry"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("otelstack", flag.ContinueOnError)
+	metrics := flags.Bool("metrics", true, "enable the metrics receiver (Prometheus)")
+	logs := flags.Bool("logs", true, "enable the logs receiver (Seq)")
+	traces := flags.Bool("traces", true, "enable the traces receiver (Jaeger)")
+	collectorImage := flags.String("collector-image", "", "override the OTEL collector image")
+	jaegerImage := flags.String("jaeger-image", "", "override the Jaeger image")
+	seqImage := flags.String("seq-image", "", "override the Seq image")
+	prometheusImage := flags.String("prometheus-image", "", "override the Prometheus image")
+	registryPrefix := flags.String("registry-prefix", "", "prefix every image with this registry mirror")
+	pullAlways := flags.Bool("pull-always", false, "always pull images, even if they are present locally")
+	envFile := flags.String("env-file", ".otelstack.env", "write the stack's environment variables to this path (empty to disable)")
+	compose := flags.String("compose", "", "write an equivalent docker compose file to this path (- for stdout) and exit")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	stack := otelstack.New(*metrics, *logs, *traces, otelstack.WithRegistry(registryConfig(*registryPrefix, *pullAlways)))
+	stack.Collector.Image = *collectorImage
+	stack.Jaeger.Image = *jaegerImage
+	stack.Seq.Image = *seqImage
+	stack.Prometheus.Image = *prometheusImage
+
+	if *compose != "" {
+		return writeCompose(stack, *compose, out)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return serve(ctx, stack, *metrics, *logs, *traces, *envFile, out)
+}
+
+func serve(ctx context.Context, stack *otelstack.Stack, metrics bool, logs bool, traces bool, envFile string, out io.Writer) (err error) {
+	fmt.Fprintln(out, "starting the stack...")
+	shutdown, err := stack.Start(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		fmt.Fprintln(out, "shutting down the stack...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		err = errors.Join(err, shutdown(shutdownCtx))
+	}()
+
+	fmt.Fprintf(out, "OTEL gRPC endpoint:  %s\n", stack.Collector.Endpoint(4317))
+	fmt.Fprintf(out, "OTEL HTTP endpoint:  %s\n", stack.Collector.Endpoint(4318))
+	fmt.Fprintf(out, "Collector metrics:   %s\n", stack.Collector.Endpoint(8888)+"/metrics")
+	if traces {
+		fmt.Fprintf(out, "Jaeger UI:           %s\n", stack.Jaeger.Endpoint(16686))
+	}
+	if logs {
+		fmt.Fprintf(out, "Seq UI:              %s\n", stack.Seq.Endpoint(80))
+	}
+	if metrics {
+		fmt.Fprintf(out, "Prometheus UI:       %s\n", stack.Prometheus.Endpoint(9090))
+	}
+
+	if envFile != "" {
+		if err := writeEnv(envFile, env(stack, metrics, logs, traces)); err != nil {
+			return err
+		}
+		defer func() {
+			if removeErr := os.Remove(envFile); removeErr != nil {
+				err = errors.Join(err, fmt.Errorf("otelstack: could not remove env file: %w", removeErr))
+			}
+		}()
+		fmt.Fprintf(out, "environment written to %s\n", envFile)
+	}
+
+	fmt.Fprintln(out, "press Ctrl-C to stop")
+	<-ctx.Done()
+
+	return nil
+}
+
+// registryConfig builds the registry configuration from the flags, reading the registry credentials
+// from OTELSTACK_REGISTRY_USERNAME and OTELSTACK_REGISTRY_PASSWORD so that they stay out of the shell history.
+func registryConfig(prefix string, pullAlways bool) *registry.Config {
+	r := &registry.Config{Prefix: prefix}
+	if pullAlways {
+		r.PullPolicy = registry.PullAlways
+	}
+
+	if username := os.Getenv("OTELSTACK_REGISTRY_USERNAME"); username != "" {
+		r.Credentials = &registry.Credentials{
+			Username: username,
+			Password: os.Getenv("OTELSTACK_REGISTRY_PASSWORD"),
+		}
+	}
+
+	return r
+}
+
+// env returns the variables needed to export to the stack, as well as the OTELSTACK_*_ENDPOINT
+// variables that let tests attach to it in external mode.
+func env(stack *otelstack.Stack, metrics bool, logs bool, traces bool) map[string]string {
+	vars := map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT":      stack.Collector.Endpoint(4317),
+		"OTEL_EXPORTER_OTLP_PROTOCOL":      "grpc",
+		otelstack.EnvCollectorGRPCEndpoint: stack.Collector.Endpoint(4317),
+		otelstack.EnvCollectorHTTPEndpoint: stack.Collector.Endpoint(4318),
+	}
+	if traces {
+		vars[otelstack.EnvJaegerEndpoint] = stack.Jaeger.Endpoint(16686)
+	}
+	if logs {
+		vars[otelstack.EnvSeqEndpoint] = stack.Seq.Endpoint(80)
+	}
+	if metrics {
+		vars[otelstack.EnvPrometheusEndpoint] = stack.Prometheus.Endpoint(9090)
+	}
+	return vars
+}
+
+func writeEnv(path string, vars map[string]string) error {
+	lines := make([]string, 0, len(vars))
+	for k, v := range vars {
+		lines = append(lines, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(lines)
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("otelstack: could not write env file: %w", err)
+	}
+	return nil
+}
+
+func writeCompose(stack *otelstack.Stack, path string, out io.Writer) error {
+	compose, err := stack.ComposeFile()
+	if err != nil {
+		return err
+	}
+
+	if path == "-" {
+		_, err = fmt.Fprint(out, compose)
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(compose), 0644); err != nil {
+		return fmt.Errorf("otelstack: could not write compose file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adreasnow/otelstack"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompose(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	err := run([]string{"-compose", "-", "-metrics=false", "-seq-image", "mirror.internal/datalust/seq:2024.3"}, &out)
+	require.NoError(t, err, "must be able to render the compose file")
+
+	assert.Contains(t, out.String(), "image: mirror.internal/datalust/seq:2024.3")
+	assert.NotContains(t, out.String(), "image: prom/prometheus")
+}
+
+func TestComposeRegistryPrefix(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	err := run([]string{"-compose", "-", "-registry-prefix", "mirror.internal"}, &out)
+	require.NoError(t, err, "must be able to render the compose file")
+
+	assert.Contains(t, out.String(), "image: mirror.internal/otel/opentelemetry-collector:0.117.0")
+	assert.Contains(t, out.String(), "image: mirror.internal/prom/prometheus:v3.2.1")
+}
+
+func TestWriteEnv(t *testing.T) {
+	t.Parallel()
+	stack := otelstack.New(false, true, false)
+	stack.Collector.Ports = map[int]nat.Port{4317: "1234", 4318: "1235"}
+	stack.Seq.Ports = map[int]nat.Port{80: "1236"}
+
+	path := filepath.Join(t.TempDir(), ".otelstack.env")
+	err := writeEnv(path, env(stack, false, true, false))
+	require.NoError(t, err, "must be able to write the env file")
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `OTELSTACK_COLLECTOR_GRPC_ENDPOINT=http://localhost:1234
+OTELSTACK_COLLECTOR_HTTP_ENDPOINT=http://localhost:1235
+OTELSTACK_SEQ_ENDPOINT=http://localhost:1236
+OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:1234
+OTEL_EXPORTER_OTLP_PROTOCOL=grpc
+`, string(contents))
+}
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+	services := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(services.Close)
+
+	stack := otelstack.New(false, true, false, otelstack.WithExternal(otelstack.External{
+		CollectorGRPC: services.URL,
+		CollectorHTTP: services.URL,
+		Seq:           services.URL,
+	}))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	out := stopWriter{stop: "press Ctrl-C to stop", cancel: cancel}
+	err := serve(ctx, stack, false, true, false, "", &out)
+	require.NoError(t, err, "must be able to serve an external stack")
+
+	assert.Contains(t, out.String(), "OTEL gRPC endpoint:  "+services.URL)
+	assert.Contains(t, out.String(), "Seq UI:              "+services.URL)
+	assert.NotContains(t, out.String(), "Jaeger UI")
+}
+
+// stopWriter cancels the context that serve waits on once it has written the stop line.
+type stopWriter struct {
+	bytes.Buffer
+	stop   string
+	cancel context.CancelFunc
+}
+
+func (w *stopWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), w.stop) {
+		w.cancel()
+	}
+	return w.Buffer.Write(p)
+}
@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+)
+
+// Auth requires clients of the OTLP receivers to authenticate, so that exporters sending the wrong
+// (or no) Authorization header are refused. Use BearerToken or BasicAuth to create one.
+//
+// The authenticators are only included in the contrib distribution of the collector, which is used
+// instead of DefaultImage when Auth is set.
+type Auth interface {
+	// Header returns the value of the Authorization header that the receivers expect.
+	Header() string
+	extension() (string, map[string]any, error)
+}
+
+const authExtension = "otelstack"
+
+// BearerToken expects an `Authorization: <Scheme> <Token>` header, where Scheme defaults to Bearer.
+type BearerToken struct {
+	Token  string
+	Scheme string
+}
+
+// Header returns the value of the Authorization header that the receivers expect.
+func (b BearerToken) Header() string {
+	return b.scheme() + " " + b.Token
+}
+
+func (b BearerToken) scheme() string {
+	if b.Scheme == "" {
+		return "Bearer"
+	}
+	return b.Scheme
+}
+
+func (b BearerToken) extension() (string, map[string]any, error) {
+	if b.Token == "" {
+		return "", nil, fmt.Errorf("bearer token must not be empty")
+	}
+	return componentID("bearertokenauth", authExtension), map[string]any{
+		"scheme": b.scheme(),
+		"token":  b.Token,
+	}, nil
+}
+
+// BasicAuth expects an `Authorization: Basic <credentials>` header with the given username and password.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Header returns the value of the Authorization header that the receivers expect.
+func (b BasicAuth) Header() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(b.Username+":"+b.Password))
+}
+
+func (b BasicAuth) extension() (string, map[string]any, error) {
+	if b.Username == "" {
+		return "", nil, fmt.Errorf("basic auth username must not be empty")
+	}
+	return componentID("basicauth", authExtension), map[string]any{
+		"htpasswd": map[string]any{"inline": b.Username + ":" + b.Password},
+	}, nil
+}
+
+// Headers returns the headers that exporters must send to authenticate to the collector, or nil when Auth is not set.
+func (c *Collector) Headers() map[string]string {
+	if c.Auth == nil {
+		return nil
+	}
+	return map[string]string{"Authorization": c.Auth.Header()}
+}
+
+// EnvHeaders returns the headers in the format of the OTEL_EXPORTER_OTLP_HEADERS environment variable.
+func (c *Collector) EnvHeaders() string {
+	if c.Auth == nil {
+		return ""
+	}
+	return "Authorization=" + url.PathEscape(c.Auth.Header())
+}
+
+// authOverlay defines the authenticator and requires it on both OTLP protocols.
+func authOverlay(auth Auth) (Tree, error) {
+	id, config, err := auth.extension()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	authenticator := map[string]any{"auth": map[string]any{"authenticator": id}}
+	return Tree{
+		"extensions": map[string]any{id: config},
+		"receivers": map[string]any{
+			"otlp": map[string]any{
+				"protocols": map[string]any{
+					"grpc": authenticator,
+					"http": authenticator,
+				},
+			},
+		},
+		"service": map[string]any{"extensions": []any{"health_check", id}},
+	}, nil
+}
@@ -0,0 +1,53 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bearer token", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Auth: BearerToken{Token: "s3cret"}}
+		require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+		assert.Equal(t, map[string]string{"Authorization": "Bearer s3cret"}, c.Headers())
+		assert.Equal(t, "Authorization=Bearer%20s3cret", c.EnvHeaders())
+		assert.Equal(t, ContribImage, c.ContainerImage())
+		assert.Contains(t, c.config, "bearertokenauth/otelstack:\n    scheme: Bearer\n    token: s3cret")
+		assert.Contains(t, c.config, "authenticator: bearertokenauth/otelstack")
+		assert.Contains(t, c.config, "extensions:\n    - health_check\n    - bearertokenauth/otelstack")
+	})
+
+	t.Run("basic auth", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Auth: BasicAuth{Username: "user", Password: "pass"}, Image: "custom/collector:1"}
+		require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+		assert.Equal(t, "Basic dXNlcjpwYXNz", c.Auth.Header())
+		assert.Equal(t, "custom/collector:1", c.ContainerImage())
+		assert.Contains(t, c.config, "inline: user:pass")
+		assert.Contains(t, c.config, "authenticator: basicauth/otelstack")
+	})
+
+	t.Run("none", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{}
+		assert.Nil(t, c.Headers())
+		assert.Empty(t, c.EnvHeaders())
+		assert.Equal(t, DefaultImage, c.ContainerImage())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Auth: BearerToken{}}
+		require.ErrorIs(t, c.generateConfig("jaeger", "seq"), ErrInvalidConfig)
+
+		c = Collector{Auth: BasicAuth{Password: "pass"}}
+		require.ErrorIs(t, c.generateConfig("jaeger", "seq"), ErrInvalidConfig)
+	})
+}
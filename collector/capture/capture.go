@@ -0,0 +1,463 @@
+// Package capture decodes the OTLP JSON written by the collector's file exporter.
+package capture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Telemetry holds everything that was captured by the collector, keeping the resource and scope of each signal.
+type Telemetry struct {
+	ResourceSpans   []ResourceSpans
+	ResourceLogs    []ResourceLogs
+	ResourceMetrics []ResourceMetrics
+}
+
+// line is a single export request written by the file exporter, which holds one of the signals.
+type line struct {
+	ResourceSpans   []ResourceSpans   `json:"resourceSpans"`
+	ResourceLogs    []ResourceLogs    `json:"resourceLogs"`
+	ResourceMetrics []ResourceMetrics `json:"resourceMetrics"`
+}
+
+// Decode reads the export requests written by the file exporter, one JSON document per line.
+func Decode(r io.Reader) (Telemetry, error) {
+	var t Telemetry
+	requests, err := DecodeRequests(r)
+	for _, request := range requests {
+		t.ResourceSpans = append(t.ResourceSpans, request.ResourceSpans...)
+		t.ResourceLogs = append(t.ResourceLogs, request.ResourceLogs...)
+		t.ResourceMetrics = append(t.ResourceMetrics, request.ResourceMetrics...)
+	}
+	return t, err
+}
+
+// DecodeRequests reads the export requests written by the file exporter, keeping each request separate.
+// Without a batch processor in the pipeline, each request is exactly what one exporter call sent.
+func DecodeRequests(r io.Reader) ([]Telemetry, error) {
+	var requests []Telemetry
+	decoder := json.NewDecoder(r)
+	for {
+		var l line
+		err := decoder.Decode(&l)
+		if errors.Is(err, io.EOF) {
+			return requests, nil
+		}
+		if err != nil {
+			return requests, fmt.Errorf("capture: could not decode the captured telemetry: %w", err)
+		}
+
+		requests = append(requests, Telemetry(l))
+	}
+}
+
+// Spans returns every captured span, regardless of its resource and scope.
+func (t Telemetry) Spans() []Span {
+	var spans []Span
+	for _, rs := range t.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			spans = append(spans, ss.Spans...)
+		}
+	}
+	return spans
+}
+
+// LogRecords returns every captured log record, regardless of its resource and scope.
+func (t Telemetry) LogRecords() []LogRecord {
+	var records []LogRecord
+	for _, rl := range t.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			records = append(records, sl.LogRecords...)
+		}
+	}
+	return records
+}
+
+// Metrics returns every captured metric, regardless of its resource and scope.
+func (t Telemetry) Metrics() []Metric {
+	var metrics []Metric
+	for _, rm := range t.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			metrics = append(metrics, sm.Metrics...)
+		}
+	}
+	return metrics
+}
+
+// DataPointAttributes returns the attributes of every captured metric data point, regardless of its
+// metric, resource and scope.
+func (t Telemetry) DataPointAttributes() []Attributes {
+	var attributes []Attributes
+	for _, m := range t.Metrics() {
+		switch {
+		case m.Gauge != nil:
+			for _, d := range m.Gauge.DataPoints {
+				attributes = append(attributes, d.Attributes)
+			}
+		case m.Sum != nil:
+			for _, d := range m.Sum.DataPoints {
+				attributes = append(attributes, d.Attributes)
+			}
+		case m.Histogram != nil:
+			for _, d := range m.Histogram.DataPoints {
+				attributes = append(attributes, d.Attributes)
+			}
+		case m.ExponentialHistogram != nil:
+			for _, d := range m.ExponentialHistogram.DataPoints {
+				attributes = append(attributes, d.Attributes)
+			}
+		case m.Summary != nil:
+			for _, d := range m.Summary.DataPoints {
+				attributes = append(attributes, d.Attributes)
+			}
+		}
+	}
+	return attributes
+}
+
+// Int64 is a 64-bit integer, which OTLP JSON encodes as a string.
+type Int64 int64
+
+// UnmarshalJSON accepts the integer as either a string or a number.
+func (i *Int64) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		u, uErr := strconv.ParseUint(s, 10, 64)
+		if uErr != nil {
+			return fmt.Errorf("capture: %s is not an integer: %w", b, err)
+		}
+		v = int64(u)
+	}
+
+	*i = Int64(v)
+	return nil
+}
+
+// Time returns the integer as a timestamp in nanoseconds since the unix epoch.
+func (i Int64) Time() time.Time {
+	return time.Unix(0, int64(i))
+}
+
+// KeyValue is a single attribute.
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// Attributes is a list of attributes.
+type Attributes []KeyValue
+
+// Get returns the value of the attribute with the given key.
+func (a Attributes) Get(key string) (any, bool) {
+	for _, kv := range a {
+		if kv.Key == key {
+			return kv.Value.Value(), true
+		}
+	}
+	return nil, false
+}
+
+// AnyValue holds an attribute or log body value, where exactly one of the fields is set.
+type AnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *Int64   `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	ArrayValue  *struct {
+		Values []AnyValue `json:"values"`
+	} `json:"arrayValue,omitempty"`
+	KvlistValue *struct {
+		Values Attributes `json:"values"`
+	} `json:"kvlistValue,omitempty"`
+	// BytesValue is base64 encoded.
+	BytesValue *string `json:"bytesValue,omitempty"`
+}
+
+// Value returns the value as a string, bool, int64, float64, []any or map[string]any,
+// or nil when no value is set.
+func (v AnyValue) Value() any {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != nil:
+		return int64(*v.IntValue)
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	case v.ArrayValue != nil:
+		values := make([]any, 0, len(v.ArrayValue.Values))
+		for _, item := range v.ArrayValue.Values {
+			values = append(values, item.Value())
+		}
+		return values
+	case v.KvlistValue != nil:
+		values := make(map[string]any, len(v.KvlistValue.Values))
+		for _, kv := range v.KvlistValue.Values {
+			values[kv.Key] = kv.Value.Value()
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// Resource is the entity that produced the telemetry.
+type Resource struct {
+	Attributes             Attributes `json:"attributes"`
+	DroppedAttributesCount int        `json:"droppedAttributesCount"`
+}
+
+// Scope is the instrumentation scope that produced the telemetry.
+type Scope struct {
+	Name                   string     `json:"name"`
+	Version                string     `json:"version"`
+	Attributes             Attributes `json:"attributes"`
+	DroppedAttributesCount int        `json:"droppedAttributesCount"`
+}
+
+// ResourceSpans holds the spans of a single resource.
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+	SchemaURL  string       `json:"schemaUrl"`
+}
+
+// ScopeSpans holds the spans of a single instrumentation scope.
+type ScopeSpans struct {
+	Scope     Scope  `json:"scope"`
+	Spans     []Span `json:"spans"`
+	SchemaURL string `json:"schemaUrl"`
+}
+
+// Span holds the data for a single span. IDs are hex encoded.
+type Span struct {
+	TraceID                string     `json:"traceId"`
+	SpanID                 string     `json:"spanId"`
+	TraceState             string     `json:"traceState"`
+	ParentSpanID           string     `json:"parentSpanId"`
+	Flags                  uint32     `json:"flags"`
+	Name                   string     `json:"name"`
+	Kind                   int        `json:"kind"`
+	StartTimeUnixNano      Int64      `json:"startTimeUnixNano"`
+	EndTimeUnixNano        Int64      `json:"endTimeUnixNano"`
+	Attributes             Attributes `json:"attributes"`
+	DroppedAttributesCount int        `json:"droppedAttributesCount"`
+	Events                 []Event    `json:"events"`
+	DroppedEventsCount     int        `json:"droppedEventsCount"`
+	Links                  []Link     `json:"links"`
+	DroppedLinksCount      int        `json:"droppedLinksCount"`
+	Status                 Status     `json:"status"`
+}
+
+// Duration returns the time between the start and end of the span.
+func (s Span) Duration() time.Duration {
+	return time.Duration(s.EndTimeUnixNano - s.StartTimeUnixNano)
+}
+
+// Event is an event recorded on a span.
+type Event struct {
+	TimeUnixNano           Int64      `json:"timeUnixNano"`
+	Name                   string     `json:"name"`
+	Attributes             Attributes `json:"attributes"`
+	DroppedAttributesCount int        `json:"droppedAttributesCount"`
+}
+
+// Link is a link from a span to another span.
+type Link struct {
+	TraceID                string     `json:"traceId"`
+	SpanID                 string     `json:"spanId"`
+	TraceState             string     `json:"traceState"`
+	Attributes             Attributes `json:"attributes"`
+	DroppedAttributesCount int        `json:"droppedAttributesCount"`
+	Flags                  uint32     `json:"flags"`
+}
+
+// Status is the status of a span, where code 0 is unset, 1 is ok and 2 is error.
+type Status struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// ResourceLogs holds the log records of a single resource.
+type ResourceLogs struct {
+	Resource  Resource    `json:"resource"`
+	ScopeLogs []ScopeLogs `json:"scopeLogs"`
+	SchemaURL string      `json:"schemaUrl"`
+}
+
+// ScopeLogs holds the log records of a single instrumentation scope.
+type ScopeLogs struct {
+	Scope      Scope       `json:"scope"`
+	LogRecords []LogRecord `json:"logRecords"`
+	SchemaURL  string      `json:"schemaUrl"`
+}
+
+// LogRecord holds the data for a single log record. IDs are hex encoded.
+type LogRecord struct {
+	TimeUnixNano           Int64      `json:"timeUnixNano"`
+	ObservedTimeUnixNano   Int64      `json:"observedTimeUnixNano"`
+	SeverityNumber         int        `json:"severityNumber"`
+	SeverityText           string     `json:"severityText"`
+	Body                   AnyValue   `json:"body"`
+	Attributes             Attributes `json:"attributes"`
+	DroppedAttributesCount int        `json:"droppedAttributesCount"`
+	Flags                  uint32     `json:"flags"`
+	TraceID                string     `json:"traceId"`
+	SpanID                 string     `json:"spanId"`
+	EventName              string     `json:"eventName"`
+}
+
+// ResourceMetrics holds the metrics of a single resource.
+type ResourceMetrics struct {
+	Resource     Resource       `json:"resource"`
+	ScopeMetrics []ScopeMetrics `json:"scopeMetrics"`
+	SchemaURL    string         `json:"schemaUrl"`
+}
+
+// ScopeMetrics holds the metrics of a single instrumentation scope.
+type ScopeMetrics struct {
+	Scope     Scope    `json:"scope"`
+	Metrics   []Metric `json:"metrics"`
+	SchemaURL string   `json:"schemaUrl"`
+}
+
+// Metric holds a single metric, where exactly one of Gauge, Sum, Histogram, ExponentialHistogram
+// or Summary is set.
+type Metric struct {
+	Name                 string                `json:"name"`
+	Description          string                `json:"description"`
+	Unit                 string                `json:"unit"`
+	Metadata             Attributes            `json:"metadata"`
+	Gauge                *Gauge                `json:"gauge,omitempty"`
+	Sum                  *Sum                  `json:"sum,omitempty"`
+	Histogram            *Histogram            `json:"histogram,omitempty"`
+	ExponentialHistogram *ExponentialHistogram `json:"exponentialHistogram,omitempty"`
+	Summary              *Summary              `json:"summary,omitempty"`
+}
+
+// Gauge holds the data points of a gauge.
+type Gauge struct {
+	DataPoints []NumberDataPoint `json:"dataPoints"`
+}
+
+// Sum holds the data points of a sum, where temporality 1 is delta and 2 is cumulative.
+type Sum struct {
+	DataPoints             []NumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+// Histogram holds the data points of a histogram.
+type Histogram struct {
+	DataPoints             []HistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+}
+
+// ExponentialHistogram holds the data points of an exponential histogram.
+type ExponentialHistogram struct {
+	DataPoints             []ExponentialHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                             `json:"aggregationTemporality"`
+}
+
+// Summary holds the data points of a summary.
+type Summary struct {
+	DataPoints []SummaryDataPoint `json:"dataPoints"`
+}
+
+// NumberDataPoint is a single value of a gauge or sum, where one of AsDouble or AsInt is set.
+type NumberDataPoint struct {
+	Attributes        Attributes `json:"attributes"`
+	StartTimeUnixNano Int64      `json:"startTimeUnixNano"`
+	TimeUnixNano      Int64      `json:"timeUnixNano"`
+	AsDouble          *float64   `json:"asDouble,omitempty"`
+	AsInt             *Int64     `json:"asInt,omitempty"`
+	Exemplars         []Exemplar `json:"exemplars"`
+	Flags             uint32     `json:"flags"`
+}
+
+// Value returns the value of the data point as a float64.
+func (d NumberDataPoint) Value() float64 {
+	if d.AsInt != nil {
+		return float64(*d.AsInt)
+	}
+	if d.AsDouble != nil {
+		return *d.AsDouble
+	}
+	return 0
+}
+
+// HistogramDataPoint is a single distribution of a histogram.
+type HistogramDataPoint struct {
+	Attributes        Attributes `json:"attributes"`
+	StartTimeUnixNano Int64      `json:"startTimeUnixNano"`
+	TimeUnixNano      Int64      `json:"timeUnixNano"`
+	Count             Int64      `json:"count"`
+	Sum               *float64   `json:"sum,omitempty"`
+	BucketCounts      []Int64    `json:"bucketCounts"`
+	ExplicitBounds    []float64  `json:"explicitBounds"`
+	Exemplars         []Exemplar `json:"exemplars"`
+	Flags             uint32     `json:"flags"`
+	Min               *float64   `json:"min,omitempty"`
+	Max               *float64   `json:"max,omitempty"`
+}
+
+// ExponentialHistogramDataPoint is a single distribution of an exponential histogram.
+type ExponentialHistogramDataPoint struct {
+	Attributes        Attributes `json:"attributes"`
+	StartTimeUnixNano Int64      `json:"startTimeUnixNano"`
+	TimeUnixNano      Int64      `json:"timeUnixNano"`
+	Count             Int64      `json:"count"`
+	Sum               *float64   `json:"sum,omitempty"`
+	Scale             int        `json:"scale"`
+	ZeroCount         Int64      `json:"zeroCount"`
+	Positive          Buckets    `json:"positive"`
+	Negative          Buckets    `json:"negative"`
+	Flags             uint32     `json:"flags"`
+	Exemplars         []Exemplar `json:"exemplars"`
+	Min               *float64   `json:"min,omitempty"`
+	Max               *float64   `json:"max,omitempty"`
+	ZeroThreshold     float64    `json:"zeroThreshold"`
+}
+
+// Buckets are the positive or negative buckets of an exponential histogram.
+type Buckets struct {
+	Offset       int     `json:"offset"`
+	BucketCounts []Int64 `json:"bucketCounts"`
+}
+
+// SummaryDataPoint is a single summary of a distribution.
+type SummaryDataPoint struct {
+	Attributes        Attributes `json:"attributes"`
+	StartTimeUnixNano Int64      `json:"startTimeUnixNano"`
+	TimeUnixNano      Int64      `json:"timeUnixNano"`
+	Count             Int64      `json:"count"`
+	Sum               float64    `json:"sum"`
+	QuantileValues    []struct {
+		Quantile float64 `json:"quantile"`
+		Value    float64 `json:"value"`
+	} `json:"quantileValues"`
+	Flags uint32 `json:"flags"`
+}
+
+// Exemplar is a sampled measurement that links a data point to a span.
+type Exemplar struct {
+	FilteredAttributes Attributes `json:"filteredAttributes"`
+	TimeUnixNano       Int64      `json:"timeUnixNano"`
+	AsDouble           *float64   `json:"asDouble,omitempty"`
+	AsInt              *Int64     `json:"asInt,omitempty"`
+	SpanID             string     `json:"spanId"`
+	TraceID            string     `json:"traceId"`
+}
@@ -0,0 +1,85 @@
+package capture
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const captured = `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"test-service"}}]},"scopeSpans":[{"scope":{"name":"test-tracer","version":"1.0.0"},"spans":[{"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174","parentSpanId":"eee19b7ec3c1b173","name":"test-span","kind":2,"startTimeUnixNano":"1544712660000000000","endTimeUnixNano":"1544712661000000000","attributes":[{"key":"http.status_code","value":{"intValue":"200"}},{"key":"tags","value":{"arrayValue":{"values":[{"stringValue":"a"},{"boolValue":true}]}}}],"events":[{"timeUnixNano":"1544712660500000000","name":"event"}],"status":{"code":2,"message":"failed"}}]}]}]}
+{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"test-service"}}]},"scopeLogs":[{"scope":{"name":"test-logger"},"logRecords":[{"timeUnixNano":"1544712660300000000","severityNumber":9,"severityText":"INFO","body":{"stringValue":"hello"},"attributes":[{"key":"ratio","value":{"doubleValue":0.5}}],"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174"}]}]}]}
+{"resourceMetrics":[{"resource":{},"scopeMetrics":[{"scope":{"name":"test-meter"},"metrics":[{"name":"requests","unit":"1","sum":{"dataPoints":[{"asInt":"3","timeUnixNano":"1544712660300000000"}],"aggregationTemporality":2,"isMonotonic":true}},{"name":"latency","histogram":{"dataPoints":[{"count":"2","sum":1.5,"bucketCounts":["1","1"],"explicitBounds":[1]}],"aggregationTemporality":2}}]}]}]}
+`
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	telemetry, err := Decode(strings.NewReader(captured))
+	require.NoError(t, err, "must be able to decode the captured telemetry")
+
+	spans := telemetry.Spans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", span.TraceID)
+	assert.Equal(t, "eee19b7ec3c1b173", span.ParentSpanID)
+	assert.Equal(t, 2, span.Kind)
+	assert.Equal(t, time.Second, span.Duration())
+	assert.Equal(t, time.Unix(1544712660, 0), span.StartTimeUnixNano.Time())
+	assert.Equal(t, Status{Code: 2, Message: "failed"}, span.Status)
+	require.Len(t, span.Events, 1)
+	assert.Equal(t, "event", span.Events[0].Name)
+
+	status, ok := span.Attributes.Get("http.status_code")
+	assert.True(t, ok)
+	assert.Equal(t, int64(200), status)
+	tags, _ := span.Attributes.Get("tags")
+	assert.Equal(t, []any{"a", true}, tags)
+	_, ok = span.Attributes.Get("missing")
+	assert.False(t, ok)
+
+	require.Len(t, telemetry.ResourceSpans, 1)
+	serviceName, _ := telemetry.ResourceSpans[0].Resource.Attributes.Get("service.name")
+	assert.Equal(t, "test-service", serviceName)
+	assert.Equal(t, Scope{Name: "test-tracer", Version: "1.0.0"}, telemetry.ResourceSpans[0].ScopeSpans[0].Scope)
+
+	records := telemetry.LogRecords()
+	require.Len(t, records, 1)
+	assert.Equal(t, "hello", records[0].Body.Value())
+	assert.Equal(t, "INFO", records[0].SeverityText)
+	assert.Equal(t, span.SpanID, records[0].SpanID)
+	ratio, _ := records[0].Attributes.Get("ratio")
+	assert.InDelta(t, 0.5, ratio, 0)
+
+	metrics := telemetry.Metrics()
+	require.Len(t, metrics, 2)
+	require.NotNil(t, metrics[0].Sum)
+	assert.True(t, metrics[0].Sum.IsMonotonic)
+	assert.InDelta(t, 3.0, metrics[0].Sum.DataPoints[0].Value(), 0)
+	require.NotNil(t, metrics[1].Histogram)
+	assert.Equal(t, []Int64{1, 1}, metrics[1].Histogram.DataPoints[0].BucketCounts)
+	assert.Equal(t, Int64(2), metrics[1].Histogram.DataPoints[0].Count)
+}
+
+func TestDecodeRequests(t *testing.T) {
+	t.Parallel()
+	requests, err := DecodeRequests(strings.NewReader(captured))
+	require.NoError(t, err, "must be able to decode the captured telemetry")
+
+	require.Len(t, requests, 3)
+	assert.Len(t, requests[0].Spans(), 1)
+	assert.Empty(t, requests[0].LogRecords())
+	assert.Len(t, requests[1].LogRecords(), 1)
+	assert.Len(t, requests[2].DataPointAttributes(), 2)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := Decode(strings.NewReader(`{"resourceSpans":[{"scopeSpans":[{"spans":[{"startTimeUnixNano":"soon"}]}]}]}`))
+	require.Error(t, err)
+
+	telemetry, err := Decode(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, telemetry.Spans())
+}
@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/adreasnow/otelstack/collector/capture"
+)
+
+// ErrCaptureDisabled is returned by Captured when the collector was not started with Capture enabled.
+var ErrCaptureDisabled = errors.New("collector: capture is not enabled on a started collector")
+
+// CapturePath is where the file exporter writes the captured telemetry inside the container when Capture is set.
+const CapturePath = "/otelstack/capture.json"
+
+const captureExporter = "file/capture"
+
+func captureOverlay() Tree {
+	return Tree{
+		"exporters": map[string]any{
+			captureExporter: map[string]any{
+				"path":           CapturePath,
+				"format":         "json",
+				"flush_interval": "100ms",
+			},
+		},
+	}
+}
+
+// Captured copies the telemetry that has been captured so far out of the container and decodes it,
+// keeping the full resource and scope of every signal. Telemetry is flushed to the file every 100ms,
+// so make sure the SDK has exported before calling Captured.
+func (c *Collector) Captured(ctx context.Context) (capture.Telemetry, error) {
+	r, err := c.openCapture(ctx)
+	if err != nil {
+		return capture.Telemetry{}, err
+	}
+	defer r.Close() //nolint:errcheck
+
+	return capture.Decode(r)
+}
+
+// capturedRequests is like Captured, but keeps each export request separate.
+func (c *Collector) capturedRequests(ctx context.Context) ([]capture.Telemetry, error) {
+	r, err := c.openCapture(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close() //nolint:errcheck
+
+	return capture.DecodeRequests(r)
+}
+
+func (c *Collector) openCapture(ctx context.Context) (io.ReadCloser, error) {
+	if !c.Capture || c.container == nil {
+		return nil, ErrCaptureDisabled
+	}
+
+	r, err := c.container.CopyFileFromContainer(ctx, CapturePath)
+	if err != nil {
+		return nil, fmt.Errorf("collector: could not copy the captured telemetry from the container: %w", err)
+	}
+	return r, nil
+}
@@ -3,29 +3,72 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/adreasnow/otelstack/registry"
 	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+// DefaultImage is the image used for the OTEL collector container.
+const DefaultImage = "otel/opentelemetry-collector:0.117.0"
+
+// ContribImage is the image used instead of DefaultImage when components that are only included in the
+// contrib distribution of the collector are configured.
+const ContribImage = "otel/opentelemetry-collector-contrib:0.117.0"
+
+// DefaultAlias is the network alias that other containers use to reach the OTEL collector.
+const DefaultAlias = "collector"
+
 // Collector hold the testcontainer, ports and network used by the OTEL collector.
+// The collector's internal metrics are exposed on port 8888, see Stats.
 // If instantiating yourself, be sure to populate Collector.Network, otherwise a new network will be generated.
+// Host is the hostname that the ports are reachable on and defaults to localhost.
+// Image overrides DefaultImage (or ContribImage, see ContainerImage) when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
+// Exporters replace the default exporter of each signal's pipeline, see Exporter.
+// ZipkinReceiver adds the zipkin receiver to the traces pipeline, which accepts Zipkin spans on port 9411.
+// ExtraExporters are exported to by every pipeline alongside its exporter, e.g. to also send everything to a dashboard.
+// Processors are inserted into the pipelines of the generated config, see Processor.
+// Overlays are deep-merged over the generated config in order, see Overlay.
+// Auth requires clients of the OTLP receivers to authenticate, see Auth.
+// TLS serves the OTLP receivers over TLS with generated certificates when set, see TLS.
+// SpanMetrics derives RED metrics from the spans into the metrics pipeline when set, see SpanMetrics.
+// ServiceGraph derives the edges between services into the metrics pipeline when set, see ServiceGraph.
+// MetadataHeaders are request headers that are promoted into the attributes of every span, log record and data point
+// (see HeaderAttributePrefix), and combined with Capture they can be read per request with ExportRequests.
+// Capture additionally writes every signal to a file in the container as OTLP JSON, which is read with Captured.
 type Collector struct {
-	Ports   map[int]nat.Port
-	config  string
-	Network *testcontainers.DockerNetwork
-	Name    string
+	Ports           map[int]nat.Port
+	config          string
+	Network         *testcontainers.DockerNetwork
+	Host            string
+	Image           string
+	Registry        *registry.Config
+	Alias           string
+	Exporters       map[Signal]Exporter
+	ExtraExporters  []Exporter
+	ZipkinReceiver  bool
+	Processors      []Processor
+	Overlays        []Overlay
+	Capture         bool
+	TLS             *TLS
+	Auth            Auth
+	MetadataHeaders []string
+	SpanMetrics     *SpanMetrics
+	ServiceGraph    *ServiceGraph
+	Name            string
+	container       testcontainers.Container
 }
 
-// Start starts the OTEL collector container.
-func (c *Collector) Start(ctx context.Context, jaegerName string, seqName string) (func(context.Context) error, error) {
+// Start starts the OTEL collector container, exporting to Jaeger and Seq through the given network aliases.
+func (c *Collector) Start(ctx context.Context, jaegerAlias string, seqAlias string) (_ func(context.Context) error, err error) {
 	emptyFunc := func(context.Context) error { return nil }
-	var err error
 
 	c.Ports = make(map[int]nat.Port)
 
@@ -36,33 +79,86 @@ func (c *Collector) Start(ctx context.Context, jaegerName string, seqName string
 		}
 	}
 
-	c.generateConfig(jaegerName, seqName)
+	if c.Alias == "" {
+		c.Alias = DefaultAlias
+	}
+
+	if err := c.generateConfig(jaegerAlias, seqAlias); err != nil {
+		return emptyFunc, err
+	}
 
-	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: testcontainers.ContainerRequest{
-			Image:        "otel/opentelemetry-collector:0.117.0",
-			ExposedPorts: []string{"4317/tcp", "4318/tcp", "13133/tcp"},
-			Networks:     []string{c.Network.Name},
-			WaitingFor:   wait.ForLog("Everything is ready. Begin running and processing data"),
-			Files: []testcontainers.ContainerFile{{
-				ContainerFilePath: "/etc/otelcol/config.yaml",
-				Reader:            strings.NewReader(c.config),
+	ports := []int{4317, 4318, 8888, 13133}
+	if c.ZipkinReceiver {
+		ports = append(ports, 9411)
+	}
+	exposed := make([]string, 0, len(ports))
+	for _, portNum := range ports {
+		exposed = append(exposed, fmt.Sprintf("%d/tcp", portNum))
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          c.ContainerImage(),
+		ExposedPorts:   exposed,
+		Networks:       []string{c.Network.Name},
+		NetworkAliases: map[string][]string{c.Network.Name: {c.Alias}},
+		WaitingFor:     wait.ForLog("Everything is ready. Begin running and processing data"),
+		Files: []testcontainers.ContainerFile{{
+			ContainerFilePath: "/etc/otelcol/config.yaml",
+			Reader:            strings.NewReader(c.config),
+			FileMode:          0644,
+		}},
+	}
+	if c.TLS != nil {
+		if err := c.TLS.Generate(c.Alias, c.host()); err != nil {
+			return emptyFunc, err
+		}
+		// the certificates are otherwise only removed by the returned shutdown func
+		defer func() {
+			if err != nil {
+				err = errors.Join(err, c.TLS.remove())
+			}
+		}()
+		for _, f := range []struct{ host, name string }{
+			{c.TLS.CAFile, "ca.pem"},
+			{c.TLS.ServerCertFile, "server.pem"},
+			{c.TLS.ServerKeyFile, "server-key.pem"},
+		} {
+			// readable by everyone, as the collector runs as a non-root user
+			req.Files = append(req.Files, testcontainers.ContainerFile{
+				HostFilePath:      f.host,
+				ContainerFilePath: TLSDir + "/" + f.name,
 				FileMode:          0644,
-			}},
-		},
-		Started: true,
+			})
+		}
+	}
+	if c.Capture {
+		// the file is created up front, as the collector runs as a non-root user that can't create directories
+		req.Files = append(req.Files, testcontainers.ContainerFile{
+			ContainerFilePath: CapturePath,
+			Reader:            strings.NewReader(""),
+			FileMode:          0666,
+		})
+	}
+	if err := c.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("collector: could not prepare the image: %w", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
 	})
 	if err != nil {
 		return emptyFunc, fmt.Errorf("collector: could not start the testcontainer: %w", err)
 	}
+	c.container = container
 
 	c.Name, err = container.Name(ctx)
 	if err != nil {
 		return emptyFunc, fmt.Errorf("collector: could not read the name of the container from the testcontainer: %w", err)
 	}
-	c.Name = c.Name[1:]
+	c.Name = strings.TrimPrefix(c.Name, "/")
 
-	for _, portNum := range []int{4317, 4318, 13133} {
+	for _, portNum := range ports {
 		c.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
 		if err != nil {
 			return emptyFunc, fmt.Errorf("collector: could not retrieve port %d from the testcontainer: %w", portNum, err)
@@ -70,12 +166,87 @@ func (c *Collector) Start(ctx context.Context, jaegerName string, seqName string
 	}
 
 	return func(ctx context.Context) error {
-		return container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
+		err := container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
+		if c.TLS != nil {
+			err = errors.Join(err, c.TLS.remove())
+		}
+		return err
 	}, nil
 }
 
-func (c *Collector) generateConfig(jaegerName string, seqName string) {
-	c.config = fmt.Sprintf(`
+// Config returns the collector configuration that is generated for the given Jaeger and Seq aliases,
+// with the overlays merged over it.
+func (c *Collector) Config(jaegerAlias string, seqAlias string) (string, error) {
+	if err := c.generateConfig(jaegerAlias, seqAlias); err != nil {
+		return "", err
+	}
+	return c.config, nil
+}
+
+// Endpoint returns the URL that the given container port of the collector can be reached on.
+// The OTLP ports use https when TLS is set.
+func (c *Collector) Endpoint(port int) string {
+	scheme := "http"
+	if c.TLS != nil && (port == 4317 || port == 4318) {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, c.host(), c.Ports[port].Int())
+}
+
+func (c *Collector) generateConfig(jaegerAlias string, seqAlias string) error {
+	receivers := map[Signal][]string{
+		Traces:  {"otlp"},
+		Logs:    {"otlp"},
+		Metrics: {"otlp"},
+	}
+	exporters := map[Signal][]string{
+		Traces:  {"otlp"},
+		Logs:    {"otlphttp/logs"},
+		Metrics: {"prometheus"},
+	}
+
+	generated := []Overlay{telemetryOverlay()}
+	if overlay := c.exportersOverlay(); overlay != nil {
+		generated = append(generated, overlay)
+		for s, e := range c.Exporters {
+			exporters[s] = []string{e.Name}
+		}
+		for _, e := range c.ExtraExporters {
+			for _, s := range signals {
+				exporters[s] = append(exporters[s], e.Name)
+			}
+		}
+	}
+	if c.ZipkinReceiver {
+		generated = append(generated, zipkinOverlay())
+		receivers[Traces] = append(receivers[Traces], "zipkin")
+	}
+	if c.TLS != nil {
+		generated = append(generated, c.TLS.overlay())
+	}
+	if c.Auth != nil {
+		auth, err := authOverlay(c.Auth)
+		if err != nil {
+			return fmt.Errorf("collector: could not generate the config: %w", err)
+		}
+		generated = append(generated, auth)
+	}
+	if c.Capture {
+		generated = append(generated, captureOverlay())
+		for _, s := range signals {
+			exporters[s] = append(exporters[s], captureExporter)
+		}
+	}
+	connectors, connectorExporters, connectorReceivers := c.connectorsOverlay()
+	if connectors != nil {
+		generated = append(generated, connectors)
+		for _, s := range signals {
+			exporters[s] = append(exporters[s], connectorExporters[s]...)
+			receivers[s] = append(receivers[s], connectorReceivers[s]...)
+		}
+	}
+
+	base := fmt.Sprintf(`
 receivers:
   otlp:
     protocols:
@@ -113,15 +284,62 @@ service:
   extensions: [health_check]
   pipelines:
     traces:
-      receivers: [otlp]
-      exporters: [otlp]
+      receivers: [%s]
+      exporters: [%s]
 
     logs:
-      receivers: [otlp]
-      exporters: [otlphttp/logs]
+      receivers: [%s]
+      exporters: [%s]
 
     metrics:
-      receivers: [otlp]
-      exporters: [prometheus]
-`, jaegerName, seqName)
+      receivers: [%s]
+      exporters: [%s]
+`, jaegerAlias, seqAlias,
+		strings.Join(receivers[Traces], ", "), strings.Join(exporters[Traces], ", "),
+		strings.Join(receivers[Logs], ", "), strings.Join(exporters[Logs], ", "),
+		strings.Join(receivers[Metrics], ", "), strings.Join(exporters[Metrics], ", "),
+	)
+
+	processors := c.Processors
+	if len(c.MetadataHeaders) > 0 {
+		generated = append(generated, metadataOverlay())
+		// processorsOverlay places it before batch, which drops the request metadata, so the headers are promoted first
+		processors = append([]Processor{headersProcessor(c.MetadataHeaders)}, processors...)
+	}
+
+	if len(processors) > 0 {
+		overlay, err := processorsOverlay(processors)
+		if err != nil {
+			return fmt.Errorf("collector: could not generate the config: %w", err)
+		}
+		generated = append(generated, overlay)
+	}
+
+	config, err := mergeConfig(base, generated, c.Overlays)
+	if err != nil {
+		return fmt.Errorf("collector: could not generate the config: %w", err)
+	}
+
+	c.config = config
+	return nil
+}
+
+func (c *Collector) host() string {
+	if c.Host == "" {
+		return "localhost"
+	}
+	return c.Host
+}
+
+// ContainerImage returns the image that the collector is started from, before the registry configuration is applied.
+// This is Image when set, otherwise ContribImage when a contrib-only component is configured, otherwise DefaultImage.
+func (c *Collector) ContainerImage() string {
+	switch {
+	case c.Image != "":
+		return c.Image
+	case c.Auth != nil, c.SpanMetrics != nil, c.ServiceGraph != nil:
+		return ContribImage
+	default:
+		return DefaultImage
+	}
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,10 +15,11 @@ import (
 func TestGenerateConfig(t *testing.T) {
 	t.Parallel()
 	c := Collector{}
-	c.generateConfig("jaeger", "seq")
+	require.NoError(t, c.generateConfig("jaeger", "seq"))
 
 	assert.Contains(t, c.config, "endpoint: http://seq/ingest/otlp")
 	assert.Contains(t, c.config, "endpoint: jaeger:4317")
+	assert.Contains(t, c.config, "port: 8888")
 }
 
 func TestCollectorStart(t *testing.T) {
@@ -36,4 +39,108 @@ func TestCollectorStart(t *testing.T) {
 
 	require.NoError(t, err, "must be able to call collector")
 	assert.Equal(t, 200, resp.StatusCode, "request should be 200")
+
+	stats, err := c.Stats(t.Context())
+	require.NoError(t, err, "must be able to read the internal metrics")
+	assert.Zero(t, stats.Dropped())
+}
+
+func TestGenerateConfigCapture(t *testing.T) {
+	t.Parallel()
+	c := Collector{Capture: true}
+	require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+	assert.Contains(t, c.config, "path: "+CapturePath)
+	assert.Contains(t, c.config, "- otlp\n        - file/capture")
+	assert.Contains(t, c.config, "- otlphttp/logs\n        - file/capture")
+	assert.Contains(t, c.config, "- prometheus\n        - file/capture")
+}
+
+func TestCapturedDisabled(t *testing.T) {
+	t.Parallel()
+	c := Collector{}
+	_, err := c.Captured(t.Context())
+	require.ErrorIs(t, err, ErrCaptureDisabled)
+}
+
+func TestCollectorStartTLS(t *testing.T) {
+	t.Parallel()
+	c := Collector{TLS: &TLS{RequireClientCert: true}}
+	shutdownFunc, err := c.Start(t.Context(), "999", "888")
+	require.NoError(t, err, "collector must be able to start")
+	t.Cleanup(func() {
+		if err := shutdownFunc(context.Background()); err != nil {
+			t.Logf("error shutting down collector: %v", err)
+		}
+	})
+
+	config, err := c.TLS.ClientConfig()
+	require.NoError(t, err, "must be able to build the client config")
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+	resp, err := client.Post(c.Endpoint(4318)+"/v1/traces", "application/json", strings.NewReader("{}"))
+	require.NoError(t, err, "must be able to call the collector over mTLS")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	config.Certificates = nil
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	_, err = client.Post(c.Endpoint(4318)+"/v1/traces", "application/json", strings.NewReader("{}"))
+	require.Error(t, err, "the collector must refuse clients without a certificate")
+}
+
+func TestCollectorStartAuth(t *testing.T) {
+	t.Parallel()
+	c := Collector{Auth: BearerToken{Token: "s3cret"}}
+	shutdownFunc, err := c.Start(t.Context(), "999", "888")
+	require.NoError(t, err, "collector must be able to start")
+	t.Cleanup(func() {
+		if err := shutdownFunc(context.Background()); err != nil {
+			t.Logf("error shutting down collector: %v", err)
+		}
+	})
+
+	post := func(headers map[string]string) int {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, c.Endpoint(4318)+"/v1/traces", strings.NewReader("{}"))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err, "must be able to call the collector")
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post(nil), "requests without credentials must be refused")
+	assert.Equal(t, http.StatusUnauthorized, post(map[string]string{"Authorization": "Bearer wrong"}), "requests with the wrong token must be refused")
+	assert.Equal(t, http.StatusOK, post(c.Headers()), "requests with the token must be accepted")
+}
+
+func TestGenerateConfigSpanMetrics(t *testing.T) {
+	t.Parallel()
+	c := Collector{SpanMetrics: &SpanMetrics{
+		Dimensions: []string{"http.route"},
+		Buckets:    []time.Duration{time.Millisecond * 5, time.Millisecond * 50},
+	}}
+	require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+	assert.Equal(t, ContribImage, c.ContainerImage())
+	assert.Contains(t, c.config, "namespace: "+SpanMetricsNamespace)
+	assert.Contains(t, c.config, "metrics_flush_interval: 1s")
+	assert.Contains(t, c.config, "buckets:\n          - 5ms\n          - 50ms")
+	assert.Contains(t, c.config, "- name: http.route")
+	assert.Contains(t, c.config, "exporters:\n        - otlp\n        - spanmetrics")
+	assert.Contains(t, c.config, "receivers:\n        - otlp\n        - spanmetrics")
+}
+
+func TestGenerateConfigServiceGraph(t *testing.T) {
+	t.Parallel()
+	c := Collector{ServiceGraph: &ServiceGraph{Dimensions: []string{"deployment.environment"}}, SpanMetrics: &SpanMetrics{}}
+	require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+	assert.Equal(t, ContribImage, c.ContainerImage())
+	assert.Contains(t, c.config, "servicegraph:\n    dimensions:\n      - deployment.environment")
+	assert.Contains(t, c.config, "ttl: 1s")
+	assert.Contains(t, c.config, "exporters:\n        - otlp\n        - spanmetrics\n        - servicegraph")
+	assert.Contains(t, c.config, "receivers:\n        - otlp\n        - spanmetrics\n        - servicegraph")
 }
@@ -0,0 +1,245 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrOverlayConflict is returned when an overlay cannot be merged over the generated config,
+// because a value in the overlay is of a different kind (map, list or scalar) to the value it replaces.
+var ErrOverlayConflict = errors.New("overlay conflicts with the generated config")
+
+// ErrInvalidConfig is returned when the merged config has a pipeline or extension that
+// references a component that is not defined.
+var ErrInvalidConfig = errors.New("invalid collector config")
+
+// Overlay is a partial collector config that is deep-merged over the generated config.
+// Maps are merged key by key, while lists and scalars in the overlay replace the generated value.
+// Use YAML or Tree to create one.
+type Overlay interface {
+	tree() (map[string]any, error)
+}
+
+// YAML is an Overlay given as a YAML document, e.g.
+//
+//	collector.YAML(`
+//	processors:
+//	  batch:
+//	service:
+//	  pipelines:
+//	    traces:
+//	      processors: [batch]
+//	`)
+type YAML string
+
+func (y YAML) tree() (map[string]any, error) {
+	var tree map[string]any
+	if err := yaml.Unmarshal([]byte(y), &tree); err != nil {
+		return nil, fmt.Errorf("could not parse overlay: %w", err)
+	}
+	return tree, nil
+}
+
+// Tree is an Overlay given as a structured config tree, mirroring the layout of the YAML config.
+type Tree map[string]any
+
+func (t Tree) tree() (map[string]any, error) {
+	// round trip through yaml so the overlay is copied and its values have the same types as the parsed config
+	b, err := yaml.Marshal(map[string]any(t))
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal overlay: %w", err)
+	}
+	return YAML(b).tree()
+}
+
+// mergeConfig parses the base config, merges the generated overlays and then the user's overlays
+// over it in order, and validates the result.
+func mergeConfig(base string, generated []Overlay, overlays []Overlay) (string, error) {
+	var config map[string]any
+	if err := yaml.Unmarshal([]byte(base), &config); err != nil {
+		return "", fmt.Errorf("could not parse the generated config: %w", err)
+	}
+
+	for _, o := range generated {
+		overlay, err := o.tree()
+		if err != nil {
+			return "", err
+		}
+
+		if err := merge(config, overlay, ""); err != nil {
+			return "", err
+		}
+	}
+
+	for i, o := range overlays {
+		overlay, err := o.tree()
+		if err != nil {
+			return "", fmt.Errorf("overlay %d: %w", i, err)
+		}
+
+		if err := merge(config, overlay, ""); err != nil {
+			return "", fmt.Errorf("overlay %d: %w", i, err)
+		}
+	}
+
+	if err := validate(config); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return "", fmt.Errorf("could not marshal the config: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+// merge deep-merges overlay into dst.
+func merge(dst map[string]any, overlay map[string]any, path string) error {
+	for key, value := range overlay {
+		keyPath := strings.TrimPrefix(path+"."+key, ".")
+
+		existing, ok := dst[key]
+		if !ok || existing == nil {
+			dst[key] = value
+			continue
+		}
+
+		if value == nil {
+			// an empty key (e.g. `batch:`) leaves the existing config untouched
+			continue
+		}
+
+		if kind(existing) != kind(value) {
+			return fmt.Errorf("%w: cannot replace the %s at %s with a %s", ErrOverlayConflict, kind(existing), keyPath, kind(value))
+		}
+
+		existingMap, isMap := existing.(map[string]any)
+		if !isMap {
+			dst[key] = value
+			continue
+		}
+
+		if err := merge(existingMap, value.(map[string]any), keyPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func kind(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "map"
+	case []any:
+		return "list"
+	default:
+		return "scalar"
+	}
+}
+
+// validate checks that the pipelines and extensions of the service only reference defined components.
+func validate(config map[string]any) error {
+	defined := func(section string) []string {
+		components, _ := config[section].(map[string]any)
+		ids := make([]string, 0, len(components))
+		for id := range components {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	receivers := defined("receivers")
+	processors := defined("processors")
+	exporters := defined("exporters")
+	connectors := defined("connectors")
+	extensions := defined("extensions")
+
+	var errs []error
+
+	service, _ := config["service"].(map[string]any)
+
+	for _, id := range stringList(service["extensions"]) {
+		if !slices.Contains(extensions, id) {
+			errs = append(errs, fmt.Errorf("%w: service references extension %q which is not defined", ErrInvalidConfig, id))
+		}
+	}
+
+	pipelines, _ := service["pipelines"].(map[string]any)
+	if len(pipelines) == 0 {
+		errs = append(errs, fmt.Errorf("%w: service has no pipelines", ErrInvalidConfig))
+	}
+
+	names := make([]string, 0, len(pipelines))
+	for name := range pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	usedAsExporter := map[string]bool{}
+	usedAsReceiver := map[string]bool{}
+
+	for _, name := range names {
+		pipeline, _ := pipelines[name].(map[string]any)
+
+		pipelineReceivers := stringList(pipeline["receivers"])
+		pipelineExporters := stringList(pipeline["exporters"])
+
+		if len(pipelineReceivers) == 0 {
+			errs = append(errs, fmt.Errorf("%w: pipeline %s has no receivers", ErrInvalidConfig, name))
+		}
+		if len(pipelineExporters) == 0 {
+			errs = append(errs, fmt.Errorf("%w: pipeline %s has no exporters", ErrInvalidConfig, name))
+		}
+
+		for _, id := range pipelineReceivers {
+			switch {
+			case slices.Contains(connectors, id):
+				usedAsReceiver[id] = true
+			case !slices.Contains(receivers, id):
+				errs = append(errs, fmt.Errorf("%w: pipeline %s references receiver %q which is not defined", ErrInvalidConfig, name, id))
+			}
+		}
+
+		for _, id := range stringList(pipeline["processors"]) {
+			if !slices.Contains(processors, id) {
+				errs = append(errs, fmt.Errorf("%w: pipeline %s references processor %q which is not defined", ErrInvalidConfig, name, id))
+			}
+		}
+
+		for _, id := range pipelineExporters {
+			switch {
+			case slices.Contains(connectors, id):
+				usedAsExporter[id] = true
+			case !slices.Contains(exporters, id):
+				errs = append(errs, fmt.Errorf("%w: pipeline %s references exporter %q which is not defined", ErrInvalidConfig, name, id))
+			}
+		}
+	}
+
+	sort.Strings(connectors)
+	for _, id := range connectors {
+		if usedAsExporter[id] != usedAsReceiver[id] {
+			errs = append(errs, fmt.Errorf("%w: connector %q must be used as both an exporter and a receiver", ErrInvalidConfig, id))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func stringList(v any) []string {
+	list, _ := v.([]any)
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		out = append(out, fmt.Sprint(item))
+	}
+	return out
+}
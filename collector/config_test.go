@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestOverlays(t *testing.T) {
+	t.Parallel()
+
+	parse := func(t *testing.T, config string) map[string]any {
+		t.Helper()
+		var tree map[string]any
+		require.NoError(t, yaml.Unmarshal([]byte(config), &tree), "config must be valid yaml")
+		return tree
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Overlays: []Overlay{YAML(`
+processors:
+  batch:
+    timeout: 1s
+service:
+  pipelines:
+    traces:
+      processors: [batch]
+`)}}
+		require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+		config := parse(t, c.config)
+		assert.Equal(t, map[string]any{"timeout": "1s"}, config["processors"].(map[string]any)["batch"])
+
+		traces := config["service"].(map[string]any)["pipelines"].(map[string]any)["traces"].(map[string]any)
+		assert.Equal(t, []any{"batch"}, traces["processors"])
+		assert.Equal(t, []any{"otlp"}, traces["receivers"], "existing keys must be kept")
+		assert.Equal(t, []any{"otlp"}, traces["exporters"], "existing keys must be kept")
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Overlays: []Overlay{
+			Tree{"exporters": map[string]any{"otlp": map[string]any{"endpoint": "tempo:4317"}}},
+			Tree{"exporters": map[string]any{"otlp": map[string]any{"compression": "none"}}},
+		}}
+		require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+		otlp := parse(t, c.config)["exporters"].(map[string]any)["otlp"].(map[string]any)
+		assert.Equal(t, "tempo:4317", otlp["endpoint"])
+		assert.Equal(t, "none", otlp["compression"])
+		assert.Equal(t, map[string]any{"insecure": true}, otlp["tls"])
+	})
+
+	t.Run("empty component", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Overlays: []Overlay{YAML(`
+extensions:
+  health_check:
+`)}}
+		require.NoError(t, c.generateConfig("jaeger", "seq"))
+		assert.Contains(t, c.config, `path: /health/status`, "an empty key must not remove the existing config")
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Overlays: []Overlay{YAML(`
+exporters:
+  otlp: [jaeger]
+`)}}
+		err := c.generateConfig("jaeger", "seq")
+		require.ErrorIs(t, err, ErrOverlayConflict)
+		assert.Contains(t, err.Error(), "exporters.otlp")
+	})
+
+	t.Run("undefined components", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Overlays: []Overlay{YAML(`
+service:
+  extensions: [health_check, pprof]
+  pipelines:
+    traces:
+      processors: [batch]
+      exporters: [otlp, zipkin]
+`)}}
+		err := c.generateConfig("jaeger", "seq")
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		assert.Contains(t, err.Error(), `extension "pprof"`)
+		assert.Contains(t, err.Error(), `processor "batch"`)
+		assert.Contains(t, err.Error(), `exporter "zipkin"`)
+	})
+
+	t.Run("connectors", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Overlays: []Overlay{YAML(`
+connectors:
+  forward:
+service:
+  pipelines:
+    traces:
+      exporters: [otlp, forward]
+`)}}
+		err := c.generateConfig("jaeger", "seq")
+		require.ErrorIs(t, err, ErrInvalidConfig, "a connector that is only exported to must be rejected")
+
+		c.Overlays = append(c.Overlays, YAML(`
+service:
+  pipelines:
+    traces/forwarded:
+      receivers: [forward]
+      exporters: [otlp]
+`))
+		require.NoError(t, c.generateConfig("jaeger", "seq"))
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Overlays: []Overlay{YAML(`exporters: [`)}}
+		require.Error(t, c.generateConfig("jaeger", "seq"))
+	})
+}
@@ -0,0 +1,124 @@
+package collector
+
+import (
+	"time"
+)
+
+// SpanMetricsNamespace is the namespace of the metrics generated by the spanmetrics connector, which Prometheus
+// exposes as traces_span_metrics_calls_total and traces_span_metrics_duration_milliseconds.
+const SpanMetricsNamespace = "traces.span.metrics"
+
+// SpanMetrics wires the spanmetrics connector from the traces pipeline into the metrics pipeline, deriving
+// request rate, error and duration (RED) metrics from the spans. The metrics are labelled with the service name,
+// span name, span kind and status code, as well as any extra Dimensions (span or resource attribute names).
+//
+// Buckets are the upper bounds of the duration histogram and default to the connector's buckets, and
+// FlushInterval is how often the metrics are emitted, defaulting to 1s.
+//
+// The connector is only included in the contrib distribution of the collector, which is used instead of
+// DefaultImage when SpanMetrics is set.
+type SpanMetrics struct {
+	Dimensions    []string
+	Buckets       []time.Duration
+	FlushInterval time.Duration
+}
+
+func (s *SpanMetrics) config() map[string]any {
+	flushInterval := s.FlushInterval
+	if flushInterval == 0 {
+		flushInterval = time.Second
+	}
+
+	histogram := map[string]any{"unit": "ms"}
+	if len(s.Buckets) > 0 {
+		buckets := make([]any, 0, len(s.Buckets))
+		for _, b := range s.Buckets {
+			buckets = append(buckets, b.String())
+		}
+		histogram["explicit"] = map[string]any{"buckets": buckets}
+	}
+
+	config := map[string]any{
+		"namespace":              SpanMetricsNamespace,
+		"metrics_flush_interval": flushInterval.String(),
+		"histogram":              histogram,
+	}
+
+	if len(s.Dimensions) > 0 {
+		dimensions := make([]any, 0, len(s.Dimensions))
+		for _, d := range s.Dimensions {
+			dimensions = append(dimensions, map[string]any{"name": d})
+		}
+		config["dimensions"] = dimensions
+	}
+
+	return config
+}
+
+// ServiceGraph wires the servicegraph connector from the traces pipeline into the metrics pipeline, deriving the
+// edges between services (e.g. traces_service_graph_request_total{client="a",server="b"}) from pairs of client and
+// server spans. Dimensions are extra span or resource attribute names that label the edges.
+//
+// FlushInterval is how often the metrics are emitted, and StoreTTL is how long a span waits for its pair before
+// it is dropped (or counted as a call to an uninstrumented service), both defaulting to 1s.
+//
+// The connector is only included in the contrib distribution of the collector, which is used instead of
+// DefaultImage when ServiceGraph is set.
+type ServiceGraph struct {
+	Dimensions    []string
+	FlushInterval time.Duration
+	StoreTTL      time.Duration
+}
+
+func (s *ServiceGraph) config() map[string]any {
+	flushInterval := s.FlushInterval
+	if flushInterval == 0 {
+		flushInterval = time.Second
+	}
+
+	storeTTL := s.StoreTTL
+	if storeTTL == 0 {
+		storeTTL = time.Second
+	}
+
+	config := map[string]any{
+		"metrics_flush_interval": flushInterval.String(),
+		"store":                  map[string]any{"ttl": storeTTL.String(), "max_items": 1000},
+	}
+
+	if len(s.Dimensions) > 0 {
+		dimensions := make([]any, 0, len(s.Dimensions))
+		for _, d := range s.Dimensions {
+			dimensions = append(dimensions, d)
+		}
+		config["dimensions"] = dimensions
+	}
+
+	return config
+}
+
+// connectorsOverlay defines the enabled connectors and returns the pipelines that each connector is an exporter of,
+// and a receiver of. The connectors are wired into the pipelines by generateConfig, as the pipeline lists are replaced
+// rather than merged by overlays.
+func (c *Collector) connectorsOverlay() (Tree, map[Signal][]string, map[Signal][]string) {
+	connectors := map[string]any{}
+	exporters := map[Signal][]string{}
+	receivers := map[Signal][]string{}
+
+	if c.SpanMetrics != nil {
+		connectors["spanmetrics"] = c.SpanMetrics.config()
+		exporters[Traces] = append(exporters[Traces], "spanmetrics")
+		receivers[Metrics] = append(receivers[Metrics], "spanmetrics")
+	}
+
+	if c.ServiceGraph != nil {
+		connectors["servicegraph"] = c.ServiceGraph.config()
+		exporters[Traces] = append(exporters[Traces], "servicegraph")
+		receivers[Metrics] = append(receivers[Metrics], "servicegraph")
+	}
+
+	if len(connectors) == 0 {
+		return nil, exporters, receivers
+	}
+	return Tree{"connectors": connectors}, exporters, receivers
+}
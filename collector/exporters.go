@@ -0,0 +1,46 @@
+package collector
+
+// Exporter replaces the default exporter of a pipeline (otlp to Jaeger for traces, otlphttp to Seq for logs and
+// prometheus for metrics), so that the signal is exported to another backend. Name is the component ID of the
+// exporter (e.g. otlp/tempo) and Config is its configuration.
+type Exporter struct {
+	Name   string
+	Config map[string]any
+}
+
+// OTLPExporter returns an exporter that sends OTLP over gRPC without TLS to the given host:port.
+func OTLPExporter(name string, endpoint string) Exporter {
+	return Exporter{
+		Name: name,
+		Config: map[string]any{
+			"endpoint": endpoint,
+			"tls":      map[string]any{"insecure": true},
+		},
+	}
+}
+
+// OTLPHTTPExporter returns an exporter that sends OTLP over HTTP to the given base URL, to which the
+// exporter appends the signal's path (e.g. /v1/logs).
+func OTLPHTTPExporter(name string, endpoint string) Exporter {
+	return Exporter{
+		Name:   name,
+		Config: map[string]any{"endpoint": endpoint},
+	}
+}
+
+// exportersOverlay defines the exporters that replace the default exporters of the pipelines, and the extra
+// exporters that every pipeline also exports to.
+func (c *Collector) exportersOverlay() Tree {
+	if len(c.Exporters) == 0 && len(c.ExtraExporters) == 0 {
+		return nil
+	}
+
+	exporters := map[string]any{}
+	for _, e := range c.Exporters {
+		exporters[e.Name] = e.Config
+	}
+	for _, e := range c.ExtraExporters {
+		exporters[e.Name] = e.Config
+	}
+	return Tree{"exporters": exporters}
+}
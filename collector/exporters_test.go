@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateConfigExporters(t *testing.T) {
+	t.Parallel()
+	c := Collector{
+		Capture: true,
+		Exporters: map[Signal]Exporter{
+			Traces: OTLPExporter("otlp/tempo", "tempo:4317"),
+			Logs:   OTLPHTTPExporter("otlphttp/loki", "http://loki:3100/otlp"),
+		},
+	}
+	require.NoError(t, c.generateConfig("", "seq"))
+
+	assert.Contains(t, c.config, "otlp/tempo:\n    endpoint: tempo:4317\n    tls:\n      insecure: true")
+	assert.Contains(t, c.config, "exporters:\n        - otlp/tempo\n        - file/capture", "the exporter must replace the default traces exporter")
+	assert.Contains(t, c.config, "otlphttp/loki:\n    endpoint: http://loki:3100/otlp")
+	assert.Contains(t, c.config, "exporters:\n        - otlphttp/loki\n        - file/capture")
+	assert.Contains(t, c.config, "exporters:\n        - prometheus\n        - file/capture", "the other pipelines must keep their exporters")
+}
+
+func TestGenerateConfigExtraExporters(t *testing.T) {
+	t.Parallel()
+	c := Collector{ExtraExporters: []Exporter{OTLPExporter("otlp/aspire", "aspire:18889")}}
+	require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+	assert.Contains(t, c.config, "otlp/aspire:\n    endpoint: aspire:18889")
+	assert.Contains(t, c.config, "exporters:\n        - otlp\n        - otlp/aspire")
+	assert.Contains(t, c.config, "exporters:\n        - otlphttp/logs\n        - otlp/aspire")
+	assert.Contains(t, c.config, "exporters:\n        - prometheus\n        - otlp/aspire")
+}
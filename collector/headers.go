@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/adreasnow/otelstack/collector/capture"
+)
+
+// HeaderAttributePrefix prefixes the attributes that request headers are promoted to, e.g. a
+// x-tenant-id header becomes the otelstack.header.x-tenant-id attribute.
+const HeaderAttributePrefix = "otelstack.header."
+
+// ExportRequest holds the headers that the collector observed on a single export request.
+type ExportRequest struct {
+	Signal  Signal
+	Headers map[string]string
+}
+
+// metadataOverlay makes the OTLP receivers include the request metadata (i.e. the headers) in the context
+// of the telemetry, so that processors can read it.
+func metadataOverlay() Tree {
+	return Tree{
+		"receivers": map[string]any{
+			"otlp": map[string]any{
+				"protocols": map[string]any{
+					"grpc": map[string]any{"include_metadata": true},
+					"http": map[string]any{"include_metadata": true},
+				},
+			},
+		},
+	}
+}
+
+// headersProcessor promotes the given headers into attributes of every span, log record and data point.
+func headersProcessor(headers []string) Processor {
+	actions := make([]AttributeAction, 0, len(headers))
+	for _, h := range headers {
+		h = strings.ToLower(h)
+		actions = append(actions, AttributeAction{
+			Key:         HeaderAttributePrefix + h,
+			Action:      Upsert,
+			FromContext: "metadata." + h,
+		})
+	}
+	return Attributes{Name: "otelstack-headers", Actions: actions}
+}
+
+// ExportRequests returns the promoted headers (see MetadataHeaders) that the collector observed on each export request,
+// in the order the requests were received. Capture must be enabled, and requests only map one to one onto exporter
+// calls when the pipelines have no batch processor.
+func (c *Collector) ExportRequests(ctx context.Context) ([]ExportRequest, error) {
+	if len(c.MetadataHeaders) == 0 {
+		return nil, fmt.Errorf("collector: no metadata headers are promoted")
+	}
+
+	requests, err := c.capturedRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]ExportRequest, 0, len(requests))
+	for _, request := range requests {
+		var signal Signal
+		var attributes []capture.Attributes
+		switch {
+		case len(request.ResourceSpans) > 0:
+			signal = Traces
+			for _, s := range request.Spans() {
+				attributes = append(attributes, s.Attributes)
+			}
+		case len(request.ResourceLogs) > 0:
+			signal = Logs
+			for _, l := range request.LogRecords() {
+				attributes = append(attributes, l.Attributes)
+			}
+		case len(request.ResourceMetrics) > 0:
+			signal = Metrics
+			attributes = request.DataPointAttributes()
+		default:
+			continue
+		}
+
+		// every item in a request was received with the same metadata, so the first one is enough
+		headers := map[string]string{}
+		if len(attributes) > 0 {
+			for _, kv := range attributes[0] {
+				if name, ok := strings.CutPrefix(kv.Key, HeaderAttributePrefix); ok {
+					headers[name] = headerValue(kv.Value.Value())
+				}
+			}
+		}
+		out = append(out, ExportRequest{Signal: signal, Headers: headers})
+	}
+
+	return out, nil
+}
+
+// headerValue flattens a header that was sent several times into a comma separated value.
+func headerValue(v any) string {
+	values, ok := v.([]any)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+
+	s := make([]string, 0, len(values))
+	for _, value := range values {
+		s = append(s, fmt.Sprint(value))
+	}
+	return strings.Join(s, ",")
+}
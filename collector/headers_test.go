@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateConfigMetadataHeaders(t *testing.T) {
+	t.Parallel()
+	c := Collector{
+		MetadataHeaders: []string{"X-Tenant-ID", "x-request-source"},
+		Processors:      []Processor{Batch{}, MemoryLimiter{LimitMiB: 100}},
+	}
+	require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+	assert.Contains(t, c.config, "include_metadata: true")
+	assert.Contains(t, c.config, "from_context: metadata.x-tenant-id")
+	assert.Contains(t, c.config, "key: "+HeaderAttributePrefix+"x-request-source")
+	assert.Contains(t, c.config, "processors:\n        - memory_limiter\n        - attributes/otelstack-headers\n        - batch")
+}
+
+func TestExportRequestsUnavailable(t *testing.T) {
+	t.Parallel()
+	c := Collector{}
+	_, err := c.ExportRequests(t.Context())
+	require.Error(t, err, "headers must be promoted to be observed")
+
+	c = Collector{MetadataHeaders: []string{"x-tenant-id"}}
+	_, err = c.ExportRequests(t.Context())
+	require.ErrorIs(t, err, ErrCaptureDisabled)
+}
+
+func TestHeaderValue(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "tenant-a", headerValue("tenant-a"))
+	assert.Equal(t, "a,b", headerValue([]any{"a", "b"}))
+}
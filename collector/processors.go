@@ -0,0 +1,328 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Signal is the type of telemetry that flows through a pipeline.
+type Signal string
+
+// The signals that the collector has pipelines for.
+const (
+	Traces  Signal = "traces"
+	Logs    Signal = "logs"
+	Metrics Signal = "metrics"
+)
+
+var signals = []Signal{Traces, Logs, Metrics}
+
+// Processor is a processor that is inserted into the pipelines of the collector. Use Batch,
+// MemoryLimiter, Attributes, Resource or Filter to create one.
+//
+// Processors are ordered within each pipeline following the collector's recommendations:
+// memory_limiter first, then the remaining processors in the order they were given, and batch last.
+type Processor interface {
+	id() string
+	signals() []Signal
+	rank() int
+	config() (map[string]any, error)
+}
+
+// ranks of the processors, where processors with the same rank keep the order they were given in
+const (
+	rankFirst = iota
+	rankDefault
+	rankLast
+)
+
+// Batch batches telemetry before it is exported.
+type Batch struct {
+	// Pipelines the processor is inserted into. Defaults to every pipeline.
+	Pipelines     []Signal
+	Timeout       time.Duration
+	SendBatchSize int
+}
+
+func (b Batch) id() string        { return "batch" }
+func (b Batch) signals() []Signal { return b.Pipelines }
+func (b Batch) rank() int         { return rankLast }
+
+func (b Batch) config() (map[string]any, error) {
+	config := map[string]any{}
+	if b.Timeout > 0 {
+		config["timeout"] = b.Timeout.String()
+	}
+	if b.SendBatchSize > 0 {
+		config["send_batch_size"] = b.SendBatchSize
+	}
+	return config, nil
+}
+
+// MemoryLimiter refuses telemetry once the collector's memory usage reaches a limit.
+// Either LimitMiB or LimitPercentage must be set.
+type MemoryLimiter struct {
+	// Pipelines the processor is inserted into. Defaults to every pipeline.
+	Pipelines []Signal
+	// CheckInterval defaults to 1s.
+	CheckInterval        time.Duration
+	LimitMiB             int
+	SpikeLimitMiB        int
+	LimitPercentage      int
+	SpikeLimitPercentage int
+}
+
+func (m MemoryLimiter) id() string        { return "memory_limiter" }
+func (m MemoryLimiter) signals() []Signal { return m.Pipelines }
+func (m MemoryLimiter) rank() int         { return rankFirst }
+
+func (m MemoryLimiter) config() (map[string]any, error) {
+	if m.LimitMiB == 0 && m.LimitPercentage == 0 {
+		return nil, fmt.Errorf("one of LimitMiB or LimitPercentage must be set")
+	}
+
+	checkInterval := m.CheckInterval
+	if checkInterval == 0 {
+		checkInterval = time.Second
+	}
+
+	config := map[string]any{"check_interval": checkInterval.String()}
+	if m.LimitMiB > 0 {
+		config["limit_mib"] = m.LimitMiB
+	}
+	if m.SpikeLimitMiB > 0 {
+		config["spike_limit_mib"] = m.SpikeLimitMiB
+	}
+	if m.LimitPercentage > 0 {
+		config["limit_percentage"] = m.LimitPercentage
+	}
+	if m.SpikeLimitPercentage > 0 {
+		config["spike_limit_percentage"] = m.SpikeLimitPercentage
+	}
+	return config, nil
+}
+
+// Action is the operation an AttributeAction performs.
+type Action string
+
+// The actions supported by the attributes and resource processors.
+const (
+	Insert  Action = "insert"
+	Update  Action = "update"
+	Upsert  Action = "upsert"
+	Delete  Action = "delete"
+	Hash    Action = "hash"
+	Extract Action = "extract"
+	Convert Action = "convert"
+)
+
+// AttributeAction modifies a single attribute. The value is taken from the first of Value,
+// FromAttribute or FromContext that is set.
+type AttributeAction struct {
+	Key           string
+	Action        Action
+	Value         any
+	FromAttribute string
+	// FromContext reads the value from the request context, e.g. "metadata.x-tenant-id" for a
+	// request header when the receiver includes metadata.
+	FromContext string
+	// Pattern is the regex used by the extract action.
+	Pattern string
+	// ConvertedType is the type used by the convert action.
+	ConvertedType string
+}
+
+func (a AttributeAction) config() map[string]any {
+	config := map[string]any{"key": a.Key, "action": string(a.Action)}
+	if a.Value != nil {
+		config["value"] = a.Value
+	}
+	if a.FromAttribute != "" {
+		config["from_attribute"] = a.FromAttribute
+	}
+	if a.FromContext != "" {
+		config["from_context"] = a.FromContext
+	}
+	if a.Pattern != "" {
+		config["pattern"] = a.Pattern
+	}
+	if a.ConvertedType != "" {
+		config["converted_type"] = a.ConvertedType
+	}
+	return config
+}
+
+func actionsConfig(actions []AttributeAction) ([]any, error) {
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("at least one action must be set")
+	}
+
+	config := make([]any, 0, len(actions))
+	for _, a := range actions {
+		if a.Key == "" && a.Action != Extract {
+			return nil, fmt.Errorf("actions must have a key")
+		}
+		config = append(config, a.config())
+	}
+	return config, nil
+}
+
+// Attributes modifies the attributes of spans, log records and metric data points.
+type Attributes struct {
+	// Name distinguishes several attributes processors, giving the id attributes/<name>.
+	Name string
+	// Pipelines the processor is inserted into. Defaults to every pipeline.
+	Pipelines []Signal
+	Actions   []AttributeAction
+}
+
+func (a Attributes) id() string        { return componentID("attributes", a.Name) }
+func (a Attributes) signals() []Signal { return a.Pipelines }
+func (a Attributes) rank() int         { return rankDefault }
+
+func (a Attributes) config() (map[string]any, error) {
+	actions, err := actionsConfig(a.Actions)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"actions": actions}, nil
+}
+
+// Resource modifies the resource attributes of all telemetry.
+type Resource struct {
+	// Name distinguishes several resource processors, giving the id resource/<name>.
+	Name string
+	// Pipelines the processor is inserted into. Defaults to every pipeline.
+	Pipelines  []Signal
+	Attributes []AttributeAction
+}
+
+func (r Resource) id() string        { return componentID("resource", r.Name) }
+func (r Resource) signals() []Signal { return r.Pipelines }
+func (r Resource) rank() int         { return rankDefault }
+
+func (r Resource) config() (map[string]any, error) {
+	actions, err := actionsConfig(r.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"attributes": actions}, nil
+}
+
+// Filter drops telemetry that matches any of the given OTTL conditions.
+type Filter struct {
+	// Name distinguishes several filter processors, giving the id filter/<name>.
+	Name string
+	// Pipelines the processor is inserted into. Defaults to the pipelines that have conditions.
+	Pipelines  []Signal
+	Spans      []string
+	SpanEvents []string
+	LogRecords []string
+	Metrics    []string
+	DataPoints []string
+	// ErrorMode is one of propagate, ignore or silent, and defaults to propagate.
+	ErrorMode string
+}
+
+func (f Filter) id() string { return componentID("filter", f.Name) }
+func (f Filter) rank() int  { return rankDefault }
+
+func (f Filter) signals() []Signal {
+	if len(f.Pipelines) > 0 {
+		return f.Pipelines
+	}
+
+	var s []Signal
+	if len(f.Spans) > 0 || len(f.SpanEvents) > 0 {
+		s = append(s, Traces)
+	}
+	if len(f.LogRecords) > 0 {
+		s = append(s, Logs)
+	}
+	if len(f.Metrics) > 0 || len(f.DataPoints) > 0 {
+		s = append(s, Metrics)
+	}
+	return s
+}
+
+func (f Filter) config() (map[string]any, error) {
+	config := map[string]any{}
+	if f.ErrorMode != "" {
+		config["error_mode"] = f.ErrorMode
+	}
+
+	conditions := func(signal string, field string, c []string) {
+		if len(c) == 0 {
+			return
+		}
+		if _, ok := config[signal]; !ok {
+			config[signal] = map[string]any{}
+		}
+		config[signal].(map[string]any)[field] = c
+	}
+	conditions("traces", "span", f.Spans)
+	conditions("traces", "spanevent", f.SpanEvents)
+	conditions("logs", "log_record", f.LogRecords)
+	conditions("metrics", "metric", f.Metrics)
+	conditions("metrics", "datapoint", f.DataPoints)
+
+	if len(f.Spans)+len(f.SpanEvents)+len(f.LogRecords)+len(f.Metrics)+len(f.DataPoints) == 0 {
+		return nil, fmt.Errorf("at least one condition must be set")
+	}
+	return config, nil
+}
+
+func componentID(componentType string, name string) string {
+	if name == "" {
+		return componentType
+	}
+	return componentType + "/" + name
+}
+
+// processorsOverlay renders the processors into an overlay that defines them and inserts them
+// into their pipelines.
+func processorsOverlay(processors []Processor) (Tree, error) {
+	ordered := slices.Clone(processors)
+	slices.SortStableFunc(ordered, func(a Processor, b Processor) int {
+		return a.rank() - b.rank()
+	})
+
+	definitions := map[string]any{}
+	pipelines := map[Signal][]any{}
+
+	for _, p := range ordered {
+		id := p.id()
+		if _, ok := definitions[id]; ok {
+			return nil, fmt.Errorf("%w: processor %q is defined more than once", ErrInvalidConfig, id)
+		}
+
+		config, err := p.config()
+		if err != nil {
+			return nil, fmt.Errorf("%w: processor %q: %w", ErrInvalidConfig, id, err)
+		}
+		definitions[id] = config
+
+		processorSignals := p.signals()
+		if len(processorSignals) == 0 {
+			processorSignals = signals
+		}
+
+		for _, s := range processorSignals {
+			if !slices.Contains(signals, s) {
+				return nil, fmt.Errorf("%w: processor %q references unknown pipeline %q", ErrInvalidConfig, id, s)
+			}
+			pipelines[s] = append(pipelines[s], id)
+		}
+	}
+
+	servicePipelines := map[string]any{}
+	for s, ids := range pipelines {
+		servicePipelines[string(s)] = map[string]any{"processors": ids}
+	}
+
+	return Tree{
+		"processors": definitions,
+		"service":    map[string]any{"pipelines": servicePipelines},
+	}, nil
+}
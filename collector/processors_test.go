@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestProcessors(t *testing.T) {
+	t.Parallel()
+
+	pipelineProcessors := func(t *testing.T, config string, signal Signal) []any {
+		t.Helper()
+		var tree map[string]any
+		require.NoError(t, yaml.Unmarshal([]byte(config), &tree), "config must be valid yaml")
+		pipeline := tree["service"].(map[string]any)["pipelines"].(map[string]any)[string(signal)].(map[string]any)
+		processors, _ := pipeline["processors"].([]any)
+		return processors
+	}
+
+	t.Run("ordered", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{Processors: []Processor{
+			Batch{Timeout: time.Second},
+			Attributes{Name: "env", Pipelines: []Signal{Traces, Logs}, Actions: []AttributeAction{
+				{Key: "deployment.environment", Value: "test", Action: Upsert},
+			}},
+			Filter{Spans: []string{`name == "healthcheck"`}},
+			MemoryLimiter{LimitMiB: 512},
+			Resource{Pipelines: []Signal{Metrics}, Attributes: []AttributeAction{
+				{Key: "host.name", Action: Delete},
+			}},
+		}}
+		require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+		assert.Equal(t, []any{"memory_limiter", "attributes/env", "filter", "batch"}, pipelineProcessors(t, c.config, Traces))
+		assert.Equal(t, []any{"memory_limiter", "attributes/env", "batch"}, pipelineProcessors(t, c.config, Logs))
+		assert.Equal(t, []any{"memory_limiter", "resource", "batch"}, pipelineProcessors(t, c.config, Metrics))
+
+		assert.Contains(t, c.config, "timeout: 1s")
+		assert.Contains(t, c.config, "limit_mib: 512")
+		assert.Contains(t, c.config, "check_interval: 1s")
+		assert.Contains(t, c.config, `- name == "healthcheck"`)
+	})
+
+	t.Run("overlay overrides", func(t *testing.T) {
+		t.Parallel()
+		c := Collector{
+			Processors: []Processor{Batch{}},
+			Overlays: []Overlay{YAML(`
+service:
+  pipelines:
+    logs:
+      processors: []
+`)},
+		}
+		require.NoError(t, c.generateConfig("jaeger", "seq"))
+		assert.Equal(t, []any{"batch"}, pipelineProcessors(t, c.config, Traces))
+		assert.Empty(t, pipelineProcessors(t, c.config, Logs))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		testData := []struct {
+			name       string
+			processors []Processor
+		}{
+			{"duplicate", []Processor{Batch{}, Batch{}}},
+			{"unknown pipeline", []Processor{Batch{Pipelines: []Signal{"profiles"}}}},
+			{"memory limiter without limit", []Processor{MemoryLimiter{}}},
+			{"attributes without actions", []Processor{Attributes{}}},
+			{"action without key", []Processor{Resource{Attributes: []AttributeAction{{Action: Delete}}}}},
+			{"filter without conditions", []Processor{Filter{Pipelines: []Signal{Traces}}}},
+		}
+		for _, tt := range testData {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+				c := Collector{Processors: tt.processors}
+				require.ErrorIs(t, c.generateConfig("jaeger", "seq"), ErrInvalidConfig)
+			})
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// telemetryOverlay exposes the collector's internal metrics on port 8888 of the container.
+func telemetryOverlay() Tree {
+	return Tree{
+		"service": map[string]any{
+			"telemetry": map[string]any{
+				"metrics": map[string]any{
+					"level": "detailed",
+					"readers": []any{map[string]any{
+						"pull": map[string]any{
+							"exporter": map[string]any{
+								"prometheus": map[string]any{"host": "0.0.0.0", "port": 8888},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// Stats holds the collector's internal counters, keyed by the id of the receiver or exporter (e.g. "otlp").
+type Stats struct {
+	Receivers map[string]ReceiverStats
+	Exporters map[string]ExporterStats
+}
+
+// ReceiverStats counts the telemetry that a receiver accepted into, or refused from, its pipelines,
+// summed across its transports.
+type ReceiverStats struct {
+	AcceptedSpans        int64
+	RefusedSpans         int64
+	AcceptedLogRecords   int64
+	RefusedLogRecords    int64
+	AcceptedMetricPoints int64
+	RefusedMetricPoints  int64
+}
+
+// ExporterStats counts the telemetry that an exporter sent, failed to send, or failed to queue.
+type ExporterStats struct {
+	SentSpans                 int64
+	SendFailedSpans           int64
+	EnqueueFailedSpans        int64
+	SentLogRecords            int64
+	SendFailedLogRecords      int64
+	EnqueueFailedLogRecords   int64
+	SentMetricPoints          int64
+	SendFailedMetricPoints    int64
+	EnqueueFailedMetricPoints int64
+}
+
+// Dropped returns the total telemetry that was refused by a receiver or failed to be exported,
+// which is zero when everything the SDK sent reached the backends.
+func (s Stats) Dropped() int64 {
+	var dropped int64
+	for _, r := range s.Receivers {
+		dropped += r.RefusedSpans + r.RefusedLogRecords + r.RefusedMetricPoints
+	}
+	for _, e := range s.Exporters {
+		dropped += e.SendFailedSpans + e.SendFailedLogRecords + e.SendFailedMetricPoints
+		dropped += e.EnqueueFailedSpans + e.EnqueueFailedLogRecords + e.EnqueueFailedMetricPoints
+	}
+	return dropped
+}
+
+// Stats reads the collector's internal metrics and returns the accepted and refused telemetry per receiver,
+// and the sent and failed telemetry per exporter.
+func (c *Collector) Stats(ctx context.Context) (Stats, error) {
+	if _, ok := c.Ports[8888]; !ok {
+		return Stats{}, fmt.Errorf("collector: the internal metrics port is not available")
+	}
+
+	endpoint := c.Endpoint(8888) + "/metrics"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Stats{}, fmt.Errorf("collector: could not create the request for %s: %w", endpoint, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Stats{}, fmt.Errorf("collector: could not get the internal metrics from %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return Stats{}, fmt.Errorf("collector: internal metrics returned status %d from %s", resp.StatusCode, endpoint)
+	}
+
+	return parseStats(resp.Body)
+}
+
+// parseStats reads the otelcol_receiver_* and otelcol_exporter_* counters from the Prometheus text format.
+func parseStats(r io.Reader) (Stats, error) {
+	stats := Stats{
+		Receivers: map[string]ReceiverStats{},
+		Exporters: map[string]ExporterStats{},
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return Stats{}, fmt.Errorf("collector: could not parse the internal metrics: %w", err)
+	}
+
+	for name, family := range families {
+		name = strings.TrimSuffix(name, "_total")
+		for _, m := range family.GetMetric() {
+			value, ok := sampleValue(family.GetType(), m)
+			if !ok {
+				continue
+			}
+
+			for _, label := range m.GetLabel() {
+				switch label.GetName() {
+				case "receiver":
+					stats.Receivers[label.GetValue()] = addReceiverSample(stats.Receivers[label.GetValue()], name, value)
+				case "exporter":
+					stats.Exporters[label.GetValue()] = addExporterSample(stats.Exporters[label.GetValue()], name, value)
+				}
+			}
+		}
+	}
+
+	// only the receivers and exporters that reported one of the counters are kept
+	for receiver, s := range stats.Receivers {
+		if s == (ReceiverStats{}) {
+			delete(stats.Receivers, receiver)
+		}
+	}
+	for exporter, s := range stats.Exporters {
+		if s == (ExporterStats{}) {
+			delete(stats.Exporters, exporter)
+		}
+	}
+
+	return stats, nil
+}
+
+// sampleValue returns the value of a counter, gauge or untyped sample, which are the types the counters are
+// exposed as depending on the collector version.
+func sampleValue(t dto.MetricType, m *dto.Metric) (int64, bool) {
+	switch t {
+	case dto.MetricType_COUNTER:
+		return int64(m.GetCounter().GetValue()), true
+	case dto.MetricType_GAUGE:
+		return int64(m.GetGauge().GetValue()), true
+	case dto.MetricType_UNTYPED:
+		return int64(m.GetUntyped().GetValue()), true
+	default:
+		return 0, false
+	}
+}
+
+func addReceiverSample(s ReceiverStats, name string, value int64) ReceiverStats {
+	switch name {
+	case "otelcol_receiver_accepted_spans":
+		s.AcceptedSpans += value
+	case "otelcol_receiver_refused_spans":
+		s.RefusedSpans += value
+	case "otelcol_receiver_accepted_log_records":
+		s.AcceptedLogRecords += value
+	case "otelcol_receiver_refused_log_records":
+		s.RefusedLogRecords += value
+	case "otelcol_receiver_accepted_metric_points":
+		s.AcceptedMetricPoints += value
+	case "otelcol_receiver_refused_metric_points":
+		s.RefusedMetricPoints += value
+	}
+	return s
+}
+
+func addExporterSample(s ExporterStats, name string, value int64) ExporterStats {
+	switch name {
+	case "otelcol_exporter_sent_spans":
+		s.SentSpans += value
+	case "otelcol_exporter_send_failed_spans":
+		s.SendFailedSpans += value
+	case "otelcol_exporter_enqueue_failed_spans":
+		s.EnqueueFailedSpans += value
+	case "otelcol_exporter_sent_log_records":
+		s.SentLogRecords += value
+	case "otelcol_exporter_send_failed_log_records":
+		s.SendFailedLogRecords += value
+	case "otelcol_exporter_enqueue_failed_log_records":
+		s.EnqueueFailedLogRecords += value
+	case "otelcol_exporter_sent_metric_points":
+		s.SentMetricPoints += value
+	case "otelcol_exporter_send_failed_metric_points":
+		s.SendFailedMetricPoints += value
+	case "otelcol_exporter_enqueue_failed_metric_points":
+		s.EnqueueFailedMetricPoints += value
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const internalMetrics = `# HELP otelcol_exporter_send_failed_spans_total Number of spans in failed attempts to send to destination.
+# TYPE otelcol_exporter_send_failed_spans_total counter
+otelcol_exporter_send_failed_spans_total{exporter="otlp",service_instance_id="0d7ad5d8",service_name="otelcol",service_version="0.117.0"} 2
+# HELP otelcol_exporter_sent_spans_total Number of spans successfully sent to destination.
+# TYPE otelcol_exporter_sent_spans_total counter
+otelcol_exporter_sent_spans_total{exporter="otlp",service_instance_id="0d7ad5d8",service_name="otelcol",service_version="0.117.0"} 10
+otelcol_exporter_sent_log_records_total{exporter="otlphttp/logs",service_instance_id="0d7ad5d8",service_name="otelcol",service_version="0.117.0"} 4
+otelcol_exporter_queue_size{data_type="traces",exporter="otlp"} 0
+# TYPE otelcol_receiver_accepted_spans_total counter
+otelcol_receiver_accepted_spans_total{receiver="otlp",service_instance_id="0d7ad5d8",transport="grpc"} 7
+otelcol_receiver_accepted_spans_total{receiver="otlp",service_instance_id="0d7ad5d8",transport="http"} 5
+otelcol_receiver_refused_metric_points{receiver="otlp",transport="grpc"} 1 1700000000000
+otelcol_process_uptime_total 12.5
+otelcol_exporter_sent_spans_total{exporter="otlp/aspire",note="a=b, c"} 3
+`
+
+func TestParseStats(t *testing.T) {
+	t.Parallel()
+	stats, err := parseStats(strings.NewReader(internalMetrics))
+	require.NoError(t, err, "must be able to parse the internal metrics")
+
+	assert.Equal(t, map[string]ReceiverStats{
+		"otlp": {AcceptedSpans: 12, RefusedMetricPoints: 1},
+	}, stats.Receivers)
+	assert.Equal(t, map[string]ExporterStats{
+		"otlp":          {SentSpans: 10, SendFailedSpans: 2},
+		"otlphttp/logs": {SentLogRecords: 4},
+		"otlp/aspire":   {SentSpans: 3},
+	}, stats.Exporters)
+	assert.Equal(t, int64(3), stats.Dropped())
+}
+
+func TestParseStatsInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := parseStats(strings.NewReader(`otelcol_receiver_accepted_spans_total{receiver="otlp"} many`))
+	require.Error(t, err)
+
+	_, err = parseStats(strings.NewReader(`otelcol_receiver_accepted_spans_total{receiver="otlp"`))
+	require.Error(t, err)
+}
+
+func TestStatsUnavailable(t *testing.T) {
+	t.Parallel()
+	c := Collector{}
+	_, err := c.Stats(t.Context())
+	require.Error(t, err)
+}
@@ -0,0 +1,211 @@
+package collector
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// TLSDir is the directory that the server certificate, key and CA are copied to in the container.
+const TLSDir = "/etc/otelcol/tls"
+
+// TLS serves the OTLP receivers over TLS, using a throwaway CA and certificates that are generated at Start.
+// The server certificate is valid for localhost, 127.0.0.1, ::1, the collector's alias and any extra Hosts.
+// When RequireClientCert is set, the receivers only accept clients presenting a certificate signed by the CA.
+//
+// Dir is where the certificates are written, and defaults to a new temporary directory that is removed
+// when the collector shuts down. CAFile, ClientCertFile, ClientKeyFile, ServerCertFile and ServerKeyFile
+// are populated once the certificates have been generated, so that exporters can be configured to trust
+// (and authenticate to) the collector.
+type TLS struct {
+	RequireClientCert bool
+	Hosts             []string
+	Dir               string
+	CAFile            string
+	ClientCertFile    string
+	ClientKeyFile     string
+	ServerCertFile    string
+	ServerKeyFile     string
+	tempDir           bool
+}
+
+// Generate writes a new CA, server certificate and client certificate to Dir, unless they have already been generated.
+func (t *TLS) Generate(hosts ...string) error {
+	if t.CAFile != "" {
+		return nil
+	}
+
+	if t.Dir == "" {
+		dir, err := os.MkdirTemp("", "otelstack-tls-")
+		if err != nil {
+			return fmt.Errorf("collector: could not create a directory for the certificates: %w", err)
+		}
+		t.Dir = dir
+		t.tempDir = true
+	}
+
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := time.Now().Add(24 * time.Hour)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("collector: could not generate the CA key: %w", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "otelstack CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("collector: could not create the CA certificate: %w", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return fmt.Errorf("collector: could not parse the CA certificate: %w", err)
+	}
+
+	server := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "otelstack collector"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	for _, h := range slices.Concat(hosts, t.Hosts) {
+		if ip := net.ParseIP(h); ip != nil {
+			server.IPAddresses = append(server.IPAddresses, ip)
+		} else if h != "" {
+			server.DNSNames = append(server.DNSNames, h)
+		}
+	}
+
+	client := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "otelstack client"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	t.CAFile = filepath.Join(t.Dir, "ca.pem")
+	if err := writePEM(t.CAFile, "CERTIFICATE", caDER); err != nil {
+		return err
+	}
+
+	t.ServerCertFile = filepath.Join(t.Dir, "server.pem")
+	t.ServerKeyFile = filepath.Join(t.Dir, "server-key.pem")
+	if err := issue(server, ca, caKey, t.ServerCertFile, t.ServerKeyFile); err != nil {
+		return err
+	}
+
+	t.ClientCertFile = filepath.Join(t.Dir, "client.pem")
+	t.ClientKeyFile = filepath.Join(t.Dir, "client-key.pem")
+	return issue(client, ca, caKey, t.ClientCertFile, t.ClientKeyFile)
+}
+
+// ClientConfig returns a TLS config that trusts the CA and, when client certificates are required, presents the client certificate.
+func (t *TLS) ClientConfig() (*tls.Config, error) {
+	caPEM, err := os.ReadFile(t.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("collector: could not read the CA certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("collector: could not parse the CA certificate in %s", t.CAFile)
+	}
+
+	config := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if t.RequireClientCert {
+		cert, err := tls.LoadX509KeyPair(t.ClientCertFile, t.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("collector: could not load the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// remove deletes the certificates if they were written to a temporary directory, so that new ones are
+// generated if the collector is started again.
+func (t *TLS) remove() error {
+	if !t.tempDir {
+		return nil
+	}
+
+	err := os.RemoveAll(t.Dir)
+	*t = TLS{RequireClientCert: t.RequireClientCert, Hosts: t.Hosts}
+	return err
+}
+
+// overlay configures both OTLP protocols to serve TLS with the certificates copied into the container.
+func (t *TLS) overlay() Tree {
+	settings := map[string]any{
+		"cert_file": TLSDir + "/server.pem",
+		"key_file":  TLSDir + "/server-key.pem",
+	}
+	if t.RequireClientCert {
+		settings["client_ca_file"] = TLSDir + "/ca.pem"
+	}
+
+	return Tree{
+		"receivers": map[string]any{
+			"otlp": map[string]any{
+				"protocols": map[string]any{
+					"grpc": map[string]any{"tls": settings},
+					"http": map[string]any{"tls": settings},
+				},
+			},
+		},
+	}
+}
+
+func issue(template *x509.Certificate, ca *x509.Certificate, caKey *ecdsa.PrivateKey, certFile string, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("collector: could not generate the key for %s: %w", template.Subject.CommonName, err)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("collector: could not create the certificate for %s: %w", template.Subject.CommonName, err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("collector: could not marshal the key for %s: %w", template.Subject.CommonName, err)
+	}
+
+	if err := writePEM(certFile, "CERTIFICATE", der); err != nil {
+		return err
+	}
+	return writePEM(keyFile, "PRIVATE KEY", keyDER)
+}
+
+func writePEM(path string, blockType string, der []byte) error {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		return fmt.Errorf("collector: could not write %s: %w", path, err)
+	}
+	return nil
+}
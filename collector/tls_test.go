@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestTLSGenerate(t *testing.T) {
+	t.Parallel()
+	c := &TLS{RequireClientCert: true, Hosts: []string{"10.0.0.1"}, Dir: t.TempDir()}
+	require.NoError(t, c.Generate("collector"), "must be able to generate the certificates")
+
+	for _, f := range []string{c.CAFile, c.ServerCertFile, c.ServerKeyFile, c.ClientCertFile, c.ClientKeyFile} {
+		assert.FileExists(t, f)
+	}
+
+	config, err := c.ClientConfig()
+	require.NoError(t, err, "must be able to build the client config")
+	assert.Len(t, config.Certificates, 1, "the client certificate must be presented for mTLS")
+
+	serverPEM, err := os.ReadFile(c.ServerCertFile)
+	require.NoError(t, err)
+	block, _ := pem.Decode(serverPEM)
+	require.NotNil(t, block)
+	server, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	for _, host := range []string{"localhost", "collector", "127.0.0.1", "10.0.0.1"} {
+		_, err := server.Verify(x509.VerifyOptions{DNSName: host, Roots: config.RootCAs})
+		assert.NoError(t, err, "the server certificate must be valid for %s", host)
+	}
+
+	caFile := c.CAFile
+	require.NoError(t, c.Generate("collector"))
+	assert.Equal(t, caFile, c.CAFile, "the certificates must only be generated once")
+}
+
+func TestTLSRemove(t *testing.T) {
+	t.Parallel()
+	c := &TLS{RequireClientCert: true}
+	require.NoError(t, c.Generate())
+	dir := c.Dir
+
+	require.NoError(t, c.remove())
+	assert.NoDirExists(t, dir)
+	assert.Empty(t, c.CAFile, "the certificates must be generated again on the next start")
+	assert.True(t, c.RequireClientCert)
+}
+
+func TestTLSRemoveOnFailedStart(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	// the network doesn't exist, so the container can't start with or without docker
+	c := Collector{TLS: &TLS{}, Network: &testcontainers.DockerNetwork{Name: "otelstack-missing-network"}}
+	_, err := c.Start(t.Context(), "jaeger", "seq")
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the certificates must be removed when the collector fails to start")
+}
+
+func TestGenerateConfigTLS(t *testing.T) {
+	t.Parallel()
+	c := Collector{TLS: &TLS{RequireClientCert: true}}
+	require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+	assert.Contains(t, c.config, "cert_file: "+TLSDir+"/server.pem")
+	assert.Contains(t, c.config, "client_ca_file: "+TLSDir+"/ca.pem")
+	assert.Equal(t, "https://localhost:0", c.Endpoint(4317))
+	assert.Equal(t, "http://localhost:0", c.Endpoint(8888))
+}
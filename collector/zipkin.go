@@ -0,0 +1,11 @@
+package collector
+
+// zipkinOverlay defines the zipkin receiver, which accepts Zipkin v1 and v2 spans over HTTP
+// (e.g. POST /api/v2/spans) and converts them into OTLP traces.
+func zipkinOverlay() Tree {
+	return Tree{
+		"receivers": map[string]any{
+			"zipkin": map[string]any{"endpoint": "0.0.0.0:9411"},
+		},
+	}
+}
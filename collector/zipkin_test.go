@@ -0,0 +1,19 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateConfigZipkinReceiver(t *testing.T) {
+	t.Parallel()
+	c := Collector{ZipkinReceiver: true}
+	require.NoError(t, c.generateConfig("jaeger", "seq"))
+
+	assert.Contains(t, c.config, "zipkin:\n    endpoint: 0.0.0.0:9411")
+	assert.Contains(t, c.config, "receivers:\n        - otlp\n        - zipkin\n", "only the traces pipeline must receive zipkin spans")
+	assert.Equal(t, 1, strings.Count(c.config, "- zipkin\n"))
+}
@@ -0,0 +1,169 @@
+package otelstack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/adreasnow/otelstack/collector"
+	"github.com/adreasnow/otelstack/jaeger"
+	"github.com/adreasnow/otelstack/prometheus"
+	"github.com/adreasnow/otelstack/seq"
+	"gopkg.in/yaml.v3"
+)
+
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+	Configs  map[string]composeConfig  `yaml:"configs,omitempty"`
+}
+
+type composeService struct {
+	Image       string                 `yaml:"image"`
+	Command     []string               `yaml:"command,omitempty"`
+	Environment map[string]string      `yaml:"environment,omitempty"`
+	Ports       []string               `yaml:"ports,omitempty"`
+	Configs     []composeServiceConfig `yaml:"configs,omitempty"`
+	Tmpfs       []string               `yaml:"tmpfs,omitempty"`
+	DependsOn   []string               `yaml:"depends_on,omitempty"`
+}
+
+type composeServiceConfig struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+}
+
+type composeConfig struct {
+	Content string `yaml:"content"`
+}
+
+// ComposeFile renders a docker compose file that is equivalent to the stack, using the same
+// images, generated configs and wiring as Start, with the network aliases as service names. The configs are inlined and mounted as files,
+// which requires docker compose v2.23.1 or newer.
+//
+// The services are published on their container ports, with the exception of the Seq UI,
+// which is published on 5380. When the collector uses TLS, the certificates are generated into
+// Collector.TLS.Dir, which is kept so that clients can trust the CA.
+func (s *Stack) ComposeFile() (string, error) {
+	if s.Tempo != nil || s.Zipkin != nil || s.Loki != nil || s.LGTM != nil || s.Aspire != nil || s.VictoriaMetrics != nil || s.Grafana != nil {
+		return "", errors.New("otelstack: the compose file can only render the collector, Jaeger, Seq and Prometheus")
+	}
+
+	f := composeFile{
+		Name:     "otelstack",
+		Services: map[string]composeService{},
+		Configs:  map[string]composeConfig{},
+	}
+
+	var jaegerName, seqName string
+	var dependsOn []string
+	collectorName := orDefault(s.Collector.Alias, collector.DefaultAlias)
+
+	if s.traces {
+		jaegerName = orDefault(s.Jaeger.Alias, jaeger.DefaultAlias)
+		dependsOn = append(dependsOn, jaegerName)
+		f.Services[jaegerName] = composeService{
+			Image:   s.Jaeger.Registry.Image(orDefault(s.Jaeger.Image, jaeger.DefaultImage)),
+			Command: []string{"--config", "/etc/jaeger/config.yaml"},
+			Ports:   []string{"16686:16686"},
+			Configs: []composeServiceConfig{{Source: "jaeger", Target: "/etc/jaeger/config.yaml"}},
+		}
+		f.Configs["jaeger"] = composeConfig{Content: s.Jaeger.Config()}
+	}
+
+	if s.logs {
+		seqName = orDefault(s.Seq.Alias, seq.DefaultAlias)
+		dependsOn = append(dependsOn, seqName)
+		f.Services[seqName] = composeService{
+			Image:       s.Seq.Registry.Image(orDefault(s.Seq.Image, seq.DefaultImage)),
+			Environment: map[string]string{"ACCEPT_EULA": "Y"},
+			Ports:       []string{"5380:80"},
+		}
+	}
+
+	collectorPorts := []string{"4317:4317", "4318:4318", "8888:8888", "13133:13133"}
+	if s.Collector.ZipkinReceiver {
+		collectorPorts = append(collectorPorts, "9411:9411")
+	}
+	f.Services[collectorName] = composeService{
+		Image:     s.Collector.Registry.Image(s.Collector.ContainerImage()),
+		Ports:     collectorPorts,
+		Configs:   []composeServiceConfig{{Source: "collector", Target: "/etc/otelcol/config.yaml"}},
+		DependsOn: dependsOn,
+	}
+	if err := s.checkExporters(); err != nil {
+		return "", err
+	}
+	s.pushMetrics()
+	collectorConfig, err := s.Collector.Config(jaegerName, seqName)
+	if err != nil {
+		return "", fmt.Errorf("otelstack: could not generate the collector config: %w", err)
+	}
+	f.Configs["collector"] = composeConfig{Content: collectorConfig}
+	if s.Collector.TLS != nil {
+		if err := s.Collector.TLS.Generate(collectorName); err != nil {
+			return "", fmt.Errorf("otelstack: could not generate the collector certificates: %w", err)
+		}
+
+		collectorService := f.Services[collectorName]
+		for name, file := range map[string]string{
+			"ca.pem":         s.Collector.TLS.CAFile,
+			"server.pem":     s.Collector.TLS.ServerCertFile,
+			"server-key.pem": s.Collector.TLS.ServerKeyFile,
+		} {
+			// inlined rather than mounted from the file, as the key is only readable by its owner on the host
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return "", fmt.Errorf("otelstack: could not read the collector certificates: %w", err)
+			}
+			source := "collector-" + strings.TrimSuffix(name, ".pem")
+			f.Configs[source] = composeConfig{Content: string(content)}
+			collectorService.Configs = append(collectorService.Configs, composeServiceConfig{Source: source, Target: collector.TLSDir + "/" + name})
+		}
+		slices.SortFunc(collectorService.Configs, func(a composeServiceConfig, b composeServiceConfig) int {
+			return strings.Compare(a.Source, b.Source)
+		})
+		f.Services[collectorName] = collectorService
+	}
+	if s.Collector.Capture {
+		// the collector runs as a non-root user, so the capture directory must be writable by anyone
+		collectorService := f.Services[collectorName]
+		collectorService.Tmpfs = []string{path.Dir(collector.CapturePath) + ":mode=1777"}
+		f.Services[collectorName] = collectorService
+	}
+
+	if s.metrics {
+		f.Services[orDefault(s.Prometheus.Alias, prometheus.DefaultAlias)] = composeService{
+			Image:     s.Prometheus.Registry.Image(orDefault(s.Prometheus.Image, prometheus.DefaultImage)),
+			Command:   s.Prometheus.Command(),
+			Ports:     []string{"9090:9090"},
+			Configs:   []composeServiceConfig{{Source: "prometheus", Target: "/etc/prometheus/prometheus.yml"}},
+			DependsOn: []string{collectorName},
+		}
+		f.Configs["prometheus"] = composeConfig{Content: s.Prometheus.Config(collectorName)}
+	}
+
+	for name, c := range f.Configs {
+		c.Content = strings.TrimSpace(c.Content) + "\n"
+		f.Configs[name] = c
+	}
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return "", fmt.Errorf("otelstack: could not marshal compose file: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+func orDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
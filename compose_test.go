@@ -0,0 +1,157 @@
+package otelstack
+
+import (
+	"testing"
+
+	"github.com/adreasnow/otelstack/collector"
+	"github.com/adreasnow/otelstack/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestComposeFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all services", func(t *testing.T) {
+		t.Parallel()
+		s := New(true, true, true)
+		out, err := s.ComposeFile()
+		require.NoError(t, err, "must be able to render the compose file")
+
+		var f composeFile
+		require.NoError(t, yaml.Unmarshal([]byte(out), &f), "compose file must be valid yaml")
+
+		assert.Len(t, f.Services, 4)
+		assert.Equal(t, collector.DefaultImage, f.Services["collector"].Image)
+		assert.ElementsMatch(t, []string{"jaeger", "seq"}, f.Services["collector"].DependsOn)
+		assert.Equal(t, []composeServiceConfig{{Source: "collector", Target: "/etc/otelcol/config.yaml"}}, f.Services["collector"].Configs)
+
+		require.Contains(t, f.Configs, "collector")
+		assert.Contains(t, f.Configs["collector"].Content, "endpoint: jaeger:4317")
+		assert.Contains(t, f.Configs["collector"].Content, "endpoint: http://seq/ingest/otlp")
+
+		require.Contains(t, f.Configs, "prometheus")
+		assert.Contains(t, f.Configs["prometheus"].Content, `- targets: ["collector:8889"]`)
+
+		require.Contains(t, f.Configs, "jaeger")
+		assert.Contains(t, f.Configs["jaeger"].Content, "jaeger_storage_exporter")
+	})
+
+	t.Run("logs only", func(t *testing.T) {
+		t.Parallel()
+		s := New(false, true, false)
+		out, err := s.ComposeFile()
+		require.NoError(t, err, "must be able to render the compose file")
+
+		var f composeFile
+		require.NoError(t, yaml.Unmarshal([]byte(out), &f), "compose file must be valid yaml")
+
+		assert.Len(t, f.Services, 2)
+		assert.Contains(t, f.Services, "seq")
+		assert.Contains(t, f.Services, "collector")
+		assert.NotContains(t, f.Configs, "prometheus")
+	})
+
+	t.Run("alias prefix", func(t *testing.T) {
+		t.Parallel()
+		s := New(true, false, true, WithAliasPrefix("a"))
+		out, err := s.ComposeFile()
+		require.NoError(t, err, "must be able to render the compose file")
+
+		var f composeFile
+		require.NoError(t, yaml.Unmarshal([]byte(out), &f), "compose file must be valid yaml")
+
+		assert.Contains(t, f.Services, "a-collector")
+		assert.Contains(t, f.Services, "a-jaeger")
+		assert.Contains(t, f.Services, "a-prometheus")
+		assert.Equal(t, []string{"a-collector"}, f.Services["a-prometheus"].DependsOn)
+		assert.Contains(t, f.Configs["collector"].Content, "endpoint: a-jaeger:4317")
+		assert.Contains(t, f.Configs["prometheus"].Content, `- targets: ["a-collector:8889"]`)
+	})
+
+	t.Run("capture", func(t *testing.T) {
+		t.Parallel()
+		s := New(false, false, true, WithCapture())
+		out, err := s.ComposeFile()
+		require.NoError(t, err, "must be able to render the compose file")
+
+		var f composeFile
+		require.NoError(t, yaml.Unmarshal([]byte(out), &f), "compose file must be valid yaml")
+
+		assert.Equal(t, []string{"/otelstack:mode=1777"}, f.Services["collector"].Tmpfs)
+		assert.Contains(t, f.Configs["collector"].Content, "path: "+collector.CapturePath)
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		t.Parallel()
+		s := New(false, false, true, WithTLS(true))
+		s.Collector.TLS.Dir = t.TempDir()
+		out, err := s.ComposeFile()
+		require.NoError(t, err, "must be able to render the compose file")
+
+		var f composeFile
+		require.NoError(t, yaml.Unmarshal([]byte(out), &f), "compose file must be valid yaml")
+
+		assert.Contains(t, f.Services["collector"].Configs, composeServiceConfig{Source: "collector-server-key", Target: collector.TLSDir + "/server-key.pem"})
+		assert.Contains(t, f.Configs["collector-ca"].Content, "BEGIN CERTIFICATE")
+		assert.Contains(t, f.Configs["collector"].Content, "client_ca_file: "+collector.TLSDir+"/ca.pem")
+		assert.FileExists(t, s.Collector.TLS.CAFile)
+	})
+
+	t.Run("auth", func(t *testing.T) {
+		t.Parallel()
+		s := New(false, false, true, WithBearerToken("s3cret"))
+		out, err := s.ComposeFile()
+		require.NoError(t, err, "must be able to render the compose file")
+
+		var f composeFile
+		require.NoError(t, yaml.Unmarshal([]byte(out), &f), "compose file must be valid yaml")
+
+		assert.Equal(t, collector.ContribImage, f.Services["collector"].Image)
+		assert.Contains(t, f.Configs["collector"].Content, "token: s3cret")
+	})
+
+	t.Run("prometheus otlp", func(t *testing.T) {
+		t.Parallel()
+		s := New(true, false, false, WithPrometheusOTLP(prometheus.NoUTF8EscapingWithSuffixes))
+		out, err := s.ComposeFile()
+		require.NoError(t, err, "must be able to render the compose file")
+
+		var f composeFile
+		require.NoError(t, yaml.Unmarshal([]byte(out), &f), "compose file must be valid yaml")
+
+		assert.Contains(t, f.Services["prometheus"].Command, "--web.enable-otlp-receiver")
+		assert.Contains(t, f.Configs["collector"].Content, "endpoint: http://prometheus:9090/api/v1/otlp")
+		assert.Contains(t, f.Configs["prometheus"].Content, "translation_strategy: NoUTF8EscapingWithSuffixes")
+		assert.NotContains(t, f.Configs["prometheus"].Content, "scrape_configs")
+	})
+
+	t.Run("zipkin receiver", func(t *testing.T) {
+		t.Parallel()
+		s := New(true, false, false, WithZipkinReceiver())
+		out, err := s.ComposeFile()
+		require.NoError(t, err, "must be able to render the compose file")
+
+		var f composeFile
+		require.NoError(t, yaml.Unmarshal([]byte(out), &f), "compose file must be valid yaml")
+
+		assert.Contains(t, f.Services["collector"].Ports, "9411:9411")
+		assert.Contains(t, f.Configs["collector"].Content, "zipkin:")
+	})
+
+	t.Run("exporter conflict", func(t *testing.T) {
+		t.Parallel()
+		s := New(true, false, false, WithPrometheusOTLP(prometheus.UnderscoreEscapingWithSuffixes))
+		s.Collector.Exporters = map[collector.Signal]collector.Exporter{collector.Metrics: collector.OTLPHTTPExporter("otlphttp/mine", "http://mine")}
+		_, err := s.ComposeFile()
+		require.Error(t, err, "must not replace a metrics exporter that was set by the user")
+	})
+
+	t.Run("unsupported backend", func(t *testing.T) {
+		t.Parallel()
+		s := New(true, false, false, WithVictoriaMetrics(false))
+		_, err := s.ComposeFile()
+		require.Error(t, err, "must not render a compose file without the stack's backends")
+	})
+}
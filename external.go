@@ -0,0 +1,201 @@
+package otelstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// External holds the endpoints of an externally managed stack, such as one run through
+// docker compose or as CI sidecars. Endpoints can be given as a URL (http://localhost:16686)
+// or as a host and port (localhost:16686). Only plain http endpoints without a path are supported.
+// Only the endpoints of the enabled signals are required. The external collector's config is its own, so
+// starting fails when any of the collector's features are set (see Collector).
+type External struct {
+	CollectorGRPC string
+	CollectorHTTP string
+	Jaeger        string
+	Seq           string
+	Prometheus    string
+}
+
+// Environment variables read by ExternalFromEnv.
+const (
+	EnvCollectorGRPCEndpoint = "OTELSTACK_COLLECTOR_GRPC_ENDPOINT"
+	EnvCollectorHTTPEndpoint = "OTELSTACK_COLLECTOR_HTTP_ENDPOINT"
+	EnvJaegerEndpoint        = "OTELSTACK_JAEGER_ENDPOINT"
+	EnvSeqEndpoint           = "OTELSTACK_SEQ_ENDPOINT"
+	EnvPrometheusEndpoint    = "OTELSTACK_PROMETHEUS_ENDPOINT"
+)
+
+// ExternalFromEnv reads the external endpoints from the OTELSTACK_*_ENDPOINT environment variables.
+// The returned bool is false when none of them are set.
+func ExternalFromEnv() (External, bool) {
+	e := External{
+		CollectorGRPC: os.Getenv(EnvCollectorGRPCEndpoint),
+		CollectorHTTP: os.Getenv(EnvCollectorHTTPEndpoint),
+		Jaeger:        os.Getenv(EnvJaegerEndpoint),
+		Seq:           os.Getenv(EnvSeqEndpoint),
+		Prometheus:    os.Getenv(EnvPrometheusEndpoint),
+	}
+
+	return e, e != External{}
+}
+
+// WithExternal attaches the stack to already running services instead of starting containers.
+// It takes precedence over the OTELSTACK_*_ENDPOINT environment variables.
+func WithExternal(e External) Option {
+	return func(s *Stack) {
+		s.external = &e
+	}
+}
+
+func (s *Stack) startExternal(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+	e := s.external
+
+	if feature, ok := collectorFeature(&s.Collector); ok {
+		return emptyFunc, fmt.Errorf("otelstack: external services do not support the collector's %s", feature)
+	}
+	if s.Tempo != nil || s.Zipkin != nil || s.Loki != nil || s.Aspire != nil {
+		return emptyFunc, errors.New("otelstack: external services do not support Tempo, Zipkin, Loki or Aspire")
+	}
+	if s.Prometheus.OTLP != nil || s.VictoriaMetrics != nil {
+		return emptyFunc, errors.New("otelstack: external services do not support Prometheus OTLP ingestion or VictoriaMetrics")
+	}
+	if s.Grafana != nil {
+		return emptyFunc, errors.New("otelstack: external services do not support Grafana")
+	}
+
+	grpcHost, grpcPort, err := parseEndpoint(e.CollectorGRPC)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("otelstack: invalid external collector gRPC endpoint: %w", err)
+	}
+	httpHost, httpPort, err := parseEndpoint(e.CollectorHTTP)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("otelstack: invalid external collector HTTP endpoint: %w", err)
+	}
+	if grpcHost != httpHost {
+		return emptyFunc, fmt.Errorf("otelstack: external collector gRPC host %q and HTTP host %q must match", grpcHost, httpHost)
+	}
+	s.Collector.Host = grpcHost
+	s.Collector.Ports = map[int]nat.Port{4317: grpcPort, 4318: httpPort}
+
+	if err := dial(ctx, grpcHost, grpcPort); err != nil {
+		return emptyFunc, fmt.Errorf("otelstack: external collector gRPC endpoint is not reachable: %w", err)
+	}
+	if err := dial(ctx, httpHost, httpPort); err != nil {
+		return emptyFunc, fmt.Errorf("otelstack: external collector HTTP endpoint is not reachable: %w", err)
+	}
+
+	if s.traces {
+		host, port, err := parseEndpoint(e.Jaeger)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("otelstack: invalid external jaeger endpoint: %w", err)
+		}
+		s.Jaeger.Host = host
+		s.Jaeger.Ports = map[int]nat.Port{16686: port}
+
+		if err := get(ctx, fmt.Sprintf("http://%s:%s/", host, port.Port())); err != nil {
+			return emptyFunc, fmt.Errorf("otelstack: external jaeger is not reachable: %w", err)
+		}
+	}
+
+	if s.logs {
+		host, port, err := parseEndpoint(e.Seq)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("otelstack: invalid external seq endpoint: %w", err)
+		}
+		s.Seq.Host = host
+		s.Seq.Ports = map[int]nat.Port{80: port}
+
+		if err := get(ctx, fmt.Sprintf("http://%s:%s/api", host, port.Port())); err != nil {
+			return emptyFunc, fmt.Errorf("otelstack: external seq is not reachable: %w", err)
+		}
+	}
+
+	if s.metrics {
+		host, port, err := parseEndpoint(e.Prometheus)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("otelstack: invalid external prometheus endpoint: %w", err)
+		}
+		s.Prometheus.Host = host
+		s.Prometheus.Ports = map[int]nat.Port{9090: port}
+
+		if err := get(ctx, fmt.Sprintf("http://%s:%s/-/ready", host, port.Port())); err != nil {
+			return emptyFunc, fmt.Errorf("otelstack: external prometheus is not reachable: %w", err)
+		}
+	}
+
+	return emptyFunc, nil
+}
+
+// parseEndpoint splits an endpoint given either as a URL or as host:port into its host and port.
+func parseEndpoint(endpoint string) (string, nat.Port, error) {
+	if endpoint == "" {
+		return "", "", fmt.Errorf("endpoint is not set")
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		u, err = url.Parse("http://" + endpoint)
+		if err != nil {
+			return "", "", fmt.Errorf("could not parse endpoint %s: %w", endpoint, err)
+		}
+	}
+
+	// the query helpers build plain http endpoints from the host and port, so anything they would drop is rejected
+	if u.Scheme != "http" {
+		return "", "", fmt.Errorf("endpoint %s must use http, the query helpers do not support %s", endpoint, u.Scheme)
+	}
+	if u.Path != "" && u.Path != "/" {
+		return "", "", fmt.Errorf("endpoint %s must not have a path, the query helpers do not support path prefixes", endpoint)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+	}
+
+	return u.Hostname(), nat.Port(port), nil
+}
+
+func dial(ctx context.Context, host string, port nat.Port) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, port.Port()))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func get(ctx context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got status %d from %s", resp.StatusCode, endpoint)
+	}
+	return nil
+}
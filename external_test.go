@@ -0,0 +1,133 @@
+package otelstack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		endpoint string
+		host     string
+		port     nat.Port
+	}{
+		{"http://localhost:16686", "localhost", "16686"},
+		{"localhost:4317", "localhost", "4317"},
+		{"127.0.0.1:4318", "127.0.0.1", "4318"},
+		{"http://seq", "seq", "80"},
+		{"http://prometheus.internal/", "prometheus.internal", "80"},
+	}
+	for _, tt := range testData {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			t.Parallel()
+			host, port, err := parseEndpoint(tt.endpoint)
+			require.NoError(t, err)
+			assert.Equal(t, tt.host, host)
+			assert.Equal(t, tt.port, port)
+		})
+	}
+
+	for _, endpoint := range []string{"", "https://prometheus.internal", "http://localhost:9090/prometheus"} {
+		t.Run("invalid "+endpoint, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := parseEndpoint(endpoint)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestExternalFromEnv(t *testing.T) {
+	t.Setenv(EnvCollectorGRPCEndpoint, "localhost:4317")
+	t.Setenv(EnvSeqEndpoint, "http://localhost:5380")
+
+	e, ok := ExternalFromEnv()
+	require.True(t, ok)
+	assert.Equal(t, "localhost:4317", e.CollectorGRPC)
+	assert.Equal(t, "http://localhost:5380", e.Seq)
+	assert.Empty(t, e.Jaeger)
+
+	s := New(false, true, false)
+	require.NotNil(t, s.external)
+	assert.Equal(t, e, *s.external)
+}
+
+func TestStartExternal(t *testing.T) {
+	collector := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(collector.Close)
+
+	seq := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api":
+			w.WriteHeader(http.StatusOK)
+		case "/api/events":
+			w.Write([]byte(`[{"MessageTemplateTokens":[{"Text":"test message"}]}]`)) //nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(seq.Close)
+
+	t.Run("normal", func(t *testing.T) {
+		s := New(false, true, false, WithExternal(External{
+			CollectorGRPC: collector.URL,
+			CollectorHTTP: collector.URL,
+			Seq:           seq.URL,
+		}))
+
+		shutdown, err := s.Start(t.Context())
+		require.NoError(t, err, "the external stack must be reachable")
+		t.Cleanup(func() {
+			require.NoError(t, shutdown(t.Context()))
+		})
+
+		assert.Equal(t, collector.URL, s.Collector.Endpoint(4317))
+
+		events, _, err := s.Seq.GetEvents(1, 1)
+		require.NoError(t, err, "must be able to get events from the external seq")
+		require.Len(t, events, 1)
+		require.Len(t, events[0].Messages, 1)
+		assert.Equal(t, "test message", events[0].Messages[0].Text)
+	})
+
+	t.Run("missing endpoint", func(t *testing.T) {
+		s := New(true, true, false, WithExternal(External{
+			CollectorGRPC: collector.URL,
+			CollectorHTTP: collector.URL,
+			Seq:           seq.URL,
+		}))
+
+		_, err := s.Start(t.Context())
+		require.Error(t, err, "prometheus must be required when metrics are enabled")
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		s := New(false, false, true, WithExternal(External{
+			CollectorGRPC: collector.URL,
+			CollectorHTTP: collector.URL,
+			Jaeger:        "localhost:1",
+		}))
+
+		_, err := s.Start(t.Context())
+		require.Error(t, err, "an unreachable jaeger must fail the start")
+	})
+
+	for name, option := range map[string]Option{"tls": WithTLS(false), "bearer token": WithBearerToken("s3cret"), "capture": WithCapture()} {
+		t.Run("unsupported "+name, func(t *testing.T) {
+			s := New(false, true, false, option, WithExternal(External{
+				CollectorGRPC: collector.URL,
+				CollectorHTTP: collector.URL,
+				Seq:           seq.URL,
+			}))
+
+			_, err := s.Start(t.Context())
+			require.Error(t, err, "external mode must not silently ignore the collector's features")
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+type explorePane struct {
+	Datasource string         `json:"datasource"`
+	Queries    []exploreQuery `json:"queries"`
+	Range      exploreRange   `json:"range"`
+}
+
+type exploreQuery struct {
+	RefID      string            `json:"refId"`
+	Datasource exploreDatasource `json:"datasource"`
+}
+
+type exploreDatasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type exploreRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ExploreURL returns the URL of Grafana's Explore view for the datasource, over the last hour.
+func (g *Grafana) ExploreURL(d Datasource) string {
+	// the panes only hold strings, so marshalling can't fail
+	panes, _ := json.Marshal(map[string]explorePane{"a": {
+		Datasource: d.UID,
+		Queries:    []exploreQuery{{RefID: "A", Datasource: exploreDatasource{Type: d.Type, UID: d.UID}}},
+		Range:      exploreRange{From: "now-1h", To: "now"},
+	}})
+
+	v := url.Values{}
+	v.Set("schemaVersion", "1")
+	v.Set("orgId", "1")
+	v.Set("panes", string(panes))
+	return fmt.Sprintf("%s/explore?%s", g.Endpoint(3000), v.Encode())
+}
@@ -0,0 +1,23 @@
+package grafana
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExploreURL(t *testing.T) {
+	t.Parallel()
+	g := Grafana{Ports: map[int]nat.Port{3000: "32768"}}
+
+	u, err := url.Parse(g.ExploreURL(Datasource{Name: "Tempo", Type: "tempo", UID: "tempo"}))
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:32768", u.Host)
+	assert.Equal(t, "/explore", u.Path)
+	assert.JSONEq(t,
+		`{"a":{"datasource":"tempo","queries":[{"refId":"A","datasource":{"type":"tempo","uid":"tempo"}}],"range":{"from":"now-1h","to":"now"}}}`,
+		u.Query().Get("panes"))
+}
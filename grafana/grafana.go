@@ -0,0 +1,177 @@
+// Package grafana holds the resources needed to start a Grafana testcontainer that is provisioned with the
+// stack's backends as datasources, for exploring the telemetry of a failing test.
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/registry"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultImage is the image used for the Grafana container.
+const DefaultImage = "grafana/grafana:11.5.2"
+
+// DefaultAlias is the network alias that other containers use to reach Grafana.
+const DefaultAlias = "grafana"
+
+// Grafana holds the testcontainer, ports and network used by Grafana. If instantiating yourself,
+// be sure to populate Grafana.Network, otherwise a new network will be generated.
+// The UI is on port 3000, and anonymous users are admins, so no login is needed.
+// Host is the hostname that the port is reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
+// Datasources are provisioned when the container starts, and must be reachable from the container's network.
+// StackDatasources are the datasources of the stack's own backends, which the stack sets on every start and which
+// are provisioned before Datasources.
+type Grafana struct {
+	Ports            map[int]nat.Port
+	Network          *testcontainers.DockerNetwork
+	Host             string
+	Image            string
+	Registry         *registry.Config
+	Alias            string
+	Name             string
+	Datasources      []Datasource
+	StackDatasources []Datasource
+}
+
+// Datasource is a datasource that Grafana is provisioned with. Type is the Grafana plugin ID (e.g. jaeger,
+// prometheus, loki, tempo or zipkin), URL is its address on the network, and UID identifies it in Explore URLs.
+type Datasource struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	UID  string `yaml:"uid"`
+	URL  string `yaml:"url"`
+}
+
+// Start starts the Grafana container.
+func (g *Grafana) Start(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+	var err error
+
+	g.Ports = make(map[int]nat.Port)
+
+	if g.Network == nil {
+		g.Network, err = network.New(ctx)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("grafana: network not provided and could not create a new one: %w", err)
+		}
+	}
+
+	if g.Alias == "" {
+		g.Alias = DefaultAlias
+	}
+
+	provisioning, err := g.Provisioning()
+	if err != nil {
+		return emptyFunc, err
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          g.image(),
+		ExposedPorts:   []string{"3000/tcp"},
+		Networks:       []string{g.Network.Name},
+		NetworkAliases: map[string][]string{g.Network.Name: {g.Alias}},
+		Env: map[string]string{
+			"GF_AUTH_ANONYMOUS_ENABLED":      "true",
+			"GF_AUTH_ANONYMOUS_ORG_ROLE":     "Admin",
+			"GF_AUTH_DISABLE_LOGIN_FORM":     "true",
+			"GF_ANALYTICS_REPORTING_ENABLED": "false",
+			"GF_ANALYTICS_CHECK_FOR_UPDATES": "false",
+		},
+		WaitingFor: wait.ForHTTP("/api/health").WithPort("3000/tcp").WithStartupTimeout(time.Minute),
+		Files: []testcontainers.ContainerFile{{
+			ContainerFilePath: "/etc/grafana/provisioning/datasources/otelstack.yaml",
+			Reader:            strings.NewReader(provisioning),
+			FileMode:          0644,
+		}},
+	}
+	if err := g.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("grafana: could not prepare the image: %w", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return emptyFunc, fmt.Errorf("grafana: could not start the testcontainer: %w", err)
+	}
+
+	g.Name, err = container.Name(ctx)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("grafana: could not read the name of the container from the testcontainer: %w", err)
+	}
+	g.Name = strings.TrimPrefix(g.Name, "/")
+
+	for _, portNum := range []int{3000} {
+		g.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
+		if err != nil {
+			return emptyFunc, fmt.Errorf("grafana: could not retrieve port %d from the testcontainer: %w", portNum, err)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
+	}, nil
+}
+
+type provisioningFile struct {
+	APIVersion  int                      `yaml:"apiVersion"`
+	Datasources []provisioningDatasource `yaml:"datasources"`
+}
+
+type provisioningDatasource struct {
+	Datasource `yaml:",inline"`
+	Access     string `yaml:"access"`
+	IsDefault  bool   `yaml:"isDefault"`
+}
+
+// ProvisionedDatasources returns StackDatasources followed by Datasources, which are the datasources that the
+// container is provisioned with.
+func (g *Grafana) ProvisionedDatasources() []Datasource {
+	return append(slices.Clone(g.StackDatasources), g.Datasources...)
+}
+
+// Provisioning returns the datasource provisioning file that the container is started with.
+// The first datasource is the default one.
+func (g *Grafana) Provisioning() (string, error) {
+	f := provisioningFile{APIVersion: 1, Datasources: []provisioningDatasource{}}
+	for i, d := range g.ProvisionedDatasources() {
+		f.Datasources = append(f.Datasources, provisioningDatasource{Datasource: d, Access: "proxy", IsDefault: i == 0})
+	}
+
+	out, err := yaml.Marshal(f)
+	if err != nil {
+		return "", fmt.Errorf("grafana: could not marshal the datasource provisioning: %w", err)
+	}
+	return string(out), nil
+}
+
+// Endpoint returns the URL that the given container port of Grafana can be reached on.
+func (g *Grafana) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", g.host(), g.Ports[port].Int())
+}
+
+func (g *Grafana) host() string {
+	if g.Host == "" {
+		return "localhost"
+	}
+	return g.Host
+}
+
+func (g *Grafana) image() string {
+	if g.Image == "" {
+		return DefaultImage
+	}
+	return g.Image
+}
@@ -0,0 +1,54 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisioning(t *testing.T) {
+	t.Parallel()
+	g := Grafana{
+		StackDatasources: []Datasource{{Name: "Jaeger", Type: "jaeger", UID: "jaeger", URL: "http://jaeger:16686"}},
+		Datasources:      []Datasource{{Name: "Prometheus", Type: "prometheus", UID: "prometheus", URL: "http://prometheus:9090"}},
+	}
+
+	provisioning, err := g.Provisioning()
+	require.NoError(t, err)
+	assert.Contains(t, provisioning, "apiVersion: 1")
+	assert.Contains(t, provisioning, "    - name: Jaeger\n      type: jaeger\n      uid: jaeger\n      url: http://jaeger:16686\n      access: proxy\n      isDefault: true")
+	assert.Contains(t, provisioning, "      url: http://prometheus:9090\n      access: proxy\n      isDefault: false")
+
+	again, err := g.Provisioning()
+	require.NoError(t, err)
+	assert.Equal(t, provisioning, again, "provisioning must not merge the datasources into Datasources")
+	assert.Len(t, g.Datasources, 1)
+}
+
+func TestGrafanaStart(t *testing.T) {
+	t.Parallel()
+	g := Grafana{Datasources: []Datasource{{Name: "Prometheus", Type: "prometheus", UID: "prometheus", URL: "http://prometheus:9090"}}}
+	shutdownFunc, err := g.Start(t.Context())
+	require.NoError(t, err, "grafana must be able to start")
+	t.Cleanup(func() {
+		if err := shutdownFunc(context.Background()); err != nil {
+			t.Logf("error shutting down grafana: %v", err)
+		}
+	})
+
+	endpoint := fmt.Sprintf("http://localhost:%d/api/datasources/uid/prometheus", g.Ports[3000].Int())
+	t.Logf("using endpoint: %s", endpoint)
+
+	resp, err := http.Get(endpoint)
+	require.NoError(t, err, "must be able to call grafana anonymously")
+	assert.Equal(t, 200, resp.StatusCode, "the datasource must be provisioned")
+
+	var datasource map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&datasource))
+	assert.Equal(t, "http://prometheus:9090", datasource["url"])
+}
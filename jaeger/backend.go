@@ -0,0 +1,104 @@
+package jaeger
+
+import (
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+)
+
+var _ backend.TraceBackend = (*Jaeger)(nil)
+
+// tracesResponse is like unmarshalStruct, but keeps every process of a trace rather than only the first.
+type tracesResponse struct {
+	Data []struct {
+		TraceID   string `json:"traceID"`
+		Spans     []Span `json:"spans"`
+		Processes map[string]struct {
+			ServiceName string     `json:"serviceName"`
+			Tags        []KeyValue `json:"tags"`
+		} `json:"processes"`
+	} `json:"data"`
+}
+
+// Traces implements backend.TraceBackend, returning the same traces as GetTraces in a backend-neutral form.
+// The tags that Jaeger derives from the span's kind, status and scope are moved into their own fields.
+func (j *Jaeger) Traces(expectedTraces int, maxRetries int, service string) ([]backend.Trace, error) {
+	u, _, err := fetchTraces(j, expectedTraces, maxRetries, service, func(u tracesResponse) int { return len(u.Data) })
+	return neutralTraces(u), err
+}
+
+func neutralTraces(u tracesResponse) []backend.Trace {
+	traces := make([]backend.Trace, 0, len(u.Data))
+	for _, t := range u.Data {
+		trace := backend.Trace{TraceID: t.TraceID}
+		for _, s := range t.Spans {
+			process := t.Processes[s.ProcessID]
+			span := backend.Span{
+				TraceID:    s.TraceID,
+				SpanID:     s.SpanID,
+				Name:       s.OperationName,
+				Service:    process.ServiceName,
+				StartTime:  time.UnixMicro(s.StartTime),
+				Duration:   time.Duration(s.Duration) * time.Microsecond,
+				Attributes: map[string]any{},
+				Resource:   tagValues(process.Tags),
+			}
+			span.Resource["service.name"] = process.ServiceName
+
+			for _, r := range s.References {
+				if r.RefType == "CHILD_OF" {
+					span.ParentSpanID = r.SpanID
+					break
+				}
+			}
+
+			for _, tag := range s.Tags {
+				switch tag.Key {
+				case "span.kind":
+					span.Kind, _ = tag.Value.(string)
+				case "otel.status_code":
+					span.Status, _ = tag.Value.(string)
+				case "otel.status_description":
+					span.StatusMessage, _ = tag.Value.(string)
+				case "otel.scope.name":
+					span.Scope, _ = tag.Value.(string)
+				case "error", "otel.scope.version":
+				default:
+					span.Attributes[tag.Key] = tagValue(tag)
+				}
+			}
+
+			for _, l := range s.Logs {
+				event := backend.Event{Time: time.UnixMicro(l.Timestamp), Attributes: map[string]any{}}
+				for _, field := range l.Fields {
+					if field.Key == "event" {
+						event.Name, _ = field.Value.(string)
+						continue
+					}
+					event.Attributes[field.Key] = tagValue(field)
+				}
+				span.Events = append(span.Events, event)
+			}
+
+			trace.Spans = append(trace.Spans, span)
+		}
+		traces = append(traces, trace)
+	}
+	return traces
+}
+
+func tagValues(tags []KeyValue) map[string]any {
+	values := make(map[string]any, len(tags))
+	for _, tag := range tags {
+		values[tag.Key] = tagValue(tag)
+	}
+	return values
+}
+
+// tagValue returns the value of a tag, with int64 tags converted back from the float64 that JSON decodes them to.
+func tagValue(tag KeyValue) any {
+	if f, ok := tag.Value.(float64); ok && tag.Type == "int64" {
+		return int64(f)
+	}
+	return tag.Value
+}
@@ -0,0 +1,94 @@
+package jaeger
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraces(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/traces" || r.URL.Query().Get("service") != "api" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"traceID":"0a","spans":[
+			{"traceID":"0a","spanID":"02","operationName":"SELECT","references":[{"refType":"CHILD_OF","traceID":"0a","spanID":"01"}],
+			 "startTime":1700000000000000,"duration":1500,"processID":"p2","tags":[
+				{"key":"span.kind","type":"string","value":"client"},
+				{"key":"otel.status_code","type":"string","value":"ERROR"},
+				{"key":"error","type":"bool","value":true},
+				{"key":"db.rows","type":"int64","value":3}
+			 ],"logs":[{"timestamp":1700000000000500,"fields":[{"key":"event","type":"string","value":"retry"},{"key":"attempt","type":"int64","value":2}]}]},
+			{"traceID":"0a","spanID":"01","operationName":"GET /users","references":[],"startTime":1700000000000000,"duration":2000,"processID":"p1","tags":[]}
+		],"processes":{
+			"p1":{"serviceName":"api","tags":[]},
+			"p2":{"serviceName":"db","tags":[{"key":"host.name","type":"string","value":"db-1"}]}
+		}}],"errors":null}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	j := Jaeger{Host: "127.0.0.1", Ports: map[int]nat.Port{16686: nat.Port(port)}}
+
+	traces, err := j.Traces(1, 1, "api")
+	require.NoError(t, err, "must be able to get the traces")
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0].Spans, 2)
+	assert.Equal(t, backend.Span{
+		TraceID:      "0a",
+		SpanID:       "02",
+		ParentSpanID: "01",
+		Name:         "SELECT",
+		Service:      "db",
+		Kind:         "client",
+		StartTime:    time.UnixMicro(1700000000000000),
+		Duration:     time.Microsecond * 1500,
+		Status:       "ERROR",
+		Attributes:   map[string]any{"db.rows": int64(3)},
+		Resource:     map[string]any{"service.name": "db", "host.name": "db-1"},
+		Events: []backend.Event{{
+			Name:       "retry",
+			Time:       time.UnixMicro(1700000000000500),
+			Attributes: map[string]any{"attempt": int64(2)},
+		}},
+	}, traces[0].Spans[0])
+	assert.Equal(t, "api", traces[0].Spans[1].Service)
+
+	_, err = j.Traces(2, 1, "api")
+	require.Error(t, err, "must fail when the expected traces are not returned")
+}
+
+func TestTracesRetry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"traceID":"0a","spans":[],"processes":{}}],"errors":null}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	j := Jaeger{Host: "127.0.0.1", Ports: map[int]nat.Port{16686: nat.Port(port)}}
+
+	traces, err := j.Traces(1, 2, "api")
+	require.NoError(t, err, "a 503 while jaeger starts must be retried")
+	assert.Len(t, traces, 1)
+}
@@ -0,0 +1,59 @@
+package jaeger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/adreasnow/otelstack/request"
+)
+
+type dependenciesStruct struct {
+	Data []struct {
+		Parent    string `json:"parent"`
+		Child     string `json:"child"`
+		CallCount int    `json:"callCount"`
+	} `json:"data"`
+	Errors any `json:"errors"`
+}
+
+// GetDependencies returns the edges between services over the last `lookback`, ordered by client and then server.
+// Jaeger only counts the calls between services, into Requests.
+// There is a retry mechanism implemented; `GetDependencies` will keep fetching every 2 seconds, for a maximum
+// of `maxRetries` times, until Jaeger returns `expectedEdges` edges.
+func (j *Jaeger) GetDependencies(expectedEdges int, maxRetries int, lookback time.Duration) ([]backend.Edge, string, error) {
+	var endpoint string
+	var edges []backend.Edge
+
+	var attempts int
+	for {
+		attempts++
+		if attempts > 1 {
+			time.Sleep(time.Second * 2)
+		}
+
+		endpoint = fmt.Sprintf("http://%s:%d/api/dependencies?endTs=%d&lookback=%d",
+			j.host(), j.Ports[16686].Int(), time.Now().UnixMilli(), lookback.Milliseconds())
+
+		var u dependenciesStruct
+		err := request.Request(endpoint, &u)
+		if err != nil && !errors.Is(err, request.ErrRetryableCode) {
+			return edges, endpoint, fmt.Errorf("jaeger: request returned a non-retryable error: %w", err)
+		}
+
+		edges = make([]backend.Edge, 0, len(u.Data))
+		for _, d := range u.Data {
+			edges = append(edges, backend.Edge{Client: d.Parent, Server: d.Child, Requests: float64(d.CallCount)})
+		}
+		backend.SortEdges(edges)
+
+		if len(edges) >= expectedEdges {
+			return edges, endpoint, nil
+		}
+
+		if attempts >= maxRetries {
+			return edges, endpoint, fmt.Errorf("jaeger: could not get %d dependencies in %d attempts", expectedEdges, maxRetries)
+		}
+	}
+}
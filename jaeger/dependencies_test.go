@@ -0,0 +1,42 @@
+package jaeger
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDependencies(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/dependencies" || r.URL.Query().Get("lookback") != "60000" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"parent":"users","child":"postgres","callCount":2},{"parent":"frontend","child":"users","callCount":3}],"errors":null}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	j := Jaeger{Host: "127.0.0.1", Ports: map[int]nat.Port{16686: nat.Port(port)}}
+
+	edges, _, err := j.GetDependencies(2, 1, time.Minute)
+	require.NoError(t, err, "must be able to get the dependencies")
+	assert.Equal(t, []backend.Edge{
+		{Client: "frontend", Server: "users", Requests: 3},
+		{Client: "users", Server: "postgres", Requests: 2},
+	}, edges)
+
+	_, _, err = j.GetDependencies(3, 1, time.Minute)
+	require.Error(t, err, "must fail when the expected edges are not returned")
+}
@@ -7,18 +7,32 @@ import (
 	"strings"
 	"time"
 
+	"github.com/adreasnow/otelstack/registry"
 	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+// DefaultImage is the image used for the Jaeger container.
+const DefaultImage = "jaegertracing/jaeger:latest"
+
+// DefaultAlias is the network alias that other containers use to reach Jaeger.
+const DefaultAlias = "jaeger"
+
 // Jaeger hold the testcontainer, ports and network used by Jaeger. If instantiating yourself,
 // be sure to populate Jaeger.Network, otherwise a new network will be generated.
+// Host is the hostname that the ports are reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
 type Jaeger struct {
-	Ports   map[int]nat.Port
-	Network *testcontainers.DockerNetwork
-	Name    string
+	Ports    map[int]nat.Port
+	Network  *testcontainers.DockerNetwork
+	Host     string
+	Image    string
+	Registry *registry.Config
+	Alias    string
+	Name     string
 }
 
 // Start starts the Jaeger container.
@@ -35,20 +49,30 @@ func (j *Jaeger) Start(ctx context.Context) (func(context.Context) error, error)
 		}
 	}
 
+	if j.Alias == "" {
+		j.Alias = DefaultAlias
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          j.image(),
+		ExposedPorts:   []string{"16686/tcp", "4318/tcp"},
+		Networks:       []string{j.Network.Name},
+		NetworkAliases: map[string][]string{j.Network.Name: {j.Alias}},
+		WaitingFor:     wait.ForLog("Everything is ready."),
+		Cmd:            []string{"--config", "/etc/jaeger/config.yaml"},
+		Files: []testcontainers.ContainerFile{{
+			ContainerFilePath: "/etc/jaeger/config.yaml",
+			Reader:            strings.NewReader(config),
+			FileMode:          0644,
+		}},
+	}
+	if err := j.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("jaeger: could not prepare the image: %w", err)
+	}
+
 	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: testcontainers.ContainerRequest{
-			Image:        "jaegertracing/jaeger:latest",
-			ExposedPorts: []string{"16686/tcp", "4318/tcp"},
-			Networks:     []string{j.Network.Name},
-			WaitingFor:   wait.ForLog("Everything is ready."),
-			Cmd:          []string{"--config", "/etc/jaeger/config.yaml"},
-			Files: []testcontainers.ContainerFile{{
-				ContainerFilePath: "/etc/jaeger/config.yaml",
-				Reader:            strings.NewReader(config),
-				FileMode:          0644,
-			}},
-		},
-		Started: true,
+		ContainerRequest: req,
+		Started:          true,
 	})
 	if err != nil {
 		return emptyFunc, fmt.Errorf("jaeger: could not start the testcontainer: %w", err)
@@ -58,7 +82,7 @@ func (j *Jaeger) Start(ctx context.Context) (func(context.Context) error, error)
 	if err != nil {
 		return emptyFunc, fmt.Errorf("jaeger: could not read the name of the container from the testcontainer: %w", err)
 	}
-	j.Name = j.Name[1:]
+	j.Name = strings.TrimPrefix(j.Name, "/")
 
 	for _, portNum := range []int{16686, 4318} {
 		j.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
@@ -72,6 +96,11 @@ func (j *Jaeger) Start(ctx context.Context) (func(context.Context) error, error)
 	}, nil
 }
 
+// Config returns the Jaeger configuration used by the container.
+func (j *Jaeger) Config() string {
+	return config
+}
+
 var config = `service:
   extensions: [jaeger_storage, jaeger_query]
   pipelines:
@@ -103,3 +132,22 @@ exporters:
   jaeger_storage_exporter:
     trace_storage: some_storage
 `
+
+// Endpoint returns the URL that the given container port of Jaeger can be reached on.
+func (j *Jaeger) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", j.host(), j.Ports[port].Int())
+}
+
+func (j *Jaeger) host() string {
+	if j.Host == "" {
+		return "localhost"
+	}
+	return j.Host
+}
+
+func (j *Jaeger) image() string {
+	if j.Image == "" {
+		return DefaultImage
+	}
+	return j.Image
+}
@@ -65,15 +65,20 @@ type Log struct {
 	Fields    []KeyValue `json:"fields"`
 }
 
-var errRespCode = fmt.Errorf("the return was not of status 200")
-
 // GetTraces takes in a service names and returns the last n traces corresponding to that service.
 // There is a retry mechanism implemented; `GetTraces` will keep fetching every 2 seconds, for a maximum
 // of `maxRetries` times, until Jaeger returns `expectedTraces` number of traces.
 func (j *Jaeger) GetTraces(expectedTraces int, maxRetries int, service string) (Traces, string, error) {
-	var traces Traces
-	endpoint := fmt.Sprintf("http://localhost:%d/api/traces?service=%s&limit=%d", j.Ports[16686].Int(), url.QueryEscape(service), expectedTraces)
+	u, endpoint, err := fetchTraces(j, expectedTraces, maxRetries, service, func(u unmarshalStruct) int { return len(u.Traces) })
+	return u.Traces, endpoint, err
+}
 
+// fetchTraces decodes the traces of the service into T, retrying as described on GetTraces until count returns
+// expectedTraces. T is the shape of the response that the caller needs.
+func fetchTraces[T any](j *Jaeger, expectedTraces int, maxRetries int, service string, count func(T) int) (T, string, error) {
+	endpoint := fmt.Sprintf("http://%s:%d/api/traces?service=%s&limit=%d", j.host(), j.Ports[16686].Int(), url.QueryEscape(service), expectedTraces)
+
+	var u T
 	var attempts int
 	for {
 		attempts++
@@ -81,20 +86,18 @@ func (j *Jaeger) GetTraces(expectedTraces int, maxRetries int, service string) (
 			time.Sleep(time.Second * 2)
 		}
 
-		var u unmarshalStruct
+		u = *new(T)
 		err := request.Request(endpoint, &u)
-		if err != nil && !errors.Is(err, errRespCode) {
-			return traces, endpoint, fmt.Errorf("jaeger: request returned a non-retryable error: %w", err)
+		if err != nil && !errors.Is(err, request.ErrRetryableCode) {
+			return u, endpoint, fmt.Errorf("jaeger: request returned a non-retryable error: %w", err)
 		}
 
-		traces = u.Traces
-
-		if len(traces) >= expectedTraces {
-			return traces, endpoint, nil
+		if count(u) >= expectedTraces {
+			return u, endpoint, nil
 		}
 
 		if attempts >= maxRetries {
-			return traces, endpoint, fmt.Errorf("jaeger: could not get %d traces in %d attempts", expectedTraces, maxRetries)
+			return u, endpoint, fmt.Errorf("jaeger: could not get %d traces in %d attempts", expectedTraces, maxRetries)
 		}
 	}
 }
@@ -0,0 +1,100 @@
+package otelstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/adreasnow/otelstack/lgtm"
+	"github.com/adreasnow/otelstack/loki"
+	"github.com/adreasnow/otelstack/tempo"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go/network"
+)
+
+// WithLGTM runs only the all-in-one grafana/otel-lgtm container instead of the collector, Jaeger, Seq and Prometheus
+// (see lgtm.LGTM). Collector.Ports point at its OTLP receivers, and Tempo, Loki and Prometheus point at its internal
+// APIs, so that Tempo.Search, Loki.QueryRange, Prometheus.GetMetrics and the stack's backends work unchanged.
+// The image runs its own collector config, so starting fails when any of the collector's own features are set
+// (see Collector), or when WithTempo or WithLoki are used, and stats are not available.
+// It takes precedence over external mode.
+func WithLGTM() Option {
+	return func(s *Stack) {
+		s.LGTM = &lgtm.LGTM{}
+	}
+}
+
+func (s *Stack) startLGTM(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+
+	if feature, ok := collectorFeature(&s.Collector); ok {
+		return emptyFunc, fmt.Errorf("otelstack: the lgtm container does not support the collector's %s", feature)
+	}
+	if s.Aspire != nil || s.Zipkin != nil {
+		return emptyFunc, errors.New("otelstack: the lgtm container does not support Aspire or Zipkin")
+	}
+	// after a previous start, Tempo and Loki are the ones pointing at the container's own APIs
+	if !s.lgtmBackends && (s.Tempo != nil || s.Loki != nil) {
+		return emptyFunc, errors.New("otelstack: the lgtm container already runs Tempo and Loki, which are set once it has started")
+	}
+	if s.Prometheus.OTLP != nil {
+		return emptyFunc, errors.New("otelstack: the lgtm container already ingests metrics into Prometheus over OTLP")
+	}
+	if s.VictoriaMetrics != nil {
+		return emptyFunc, errors.New("otelstack: the lgtm container does not support VictoriaMetrics")
+	}
+	if s.Grafana != nil {
+		return emptyFunc, errors.New("otelstack: the lgtm container already runs Grafana, on LGTM.Ports[3000]")
+	}
+
+	var shutdownFuncs []func(context.Context) error
+	shutdown := func(ctx context.Context) error {
+		var err error
+		// in reverse, so that the network is removed last
+		for i := len(shutdownFuncs) - 1; i >= 0; i-- {
+			err = errors.Join(err, shutdownFuncs[i](ctx))
+		}
+		if err != nil {
+			return fmt.Errorf("otelstack: error shutting down the lgtm container: %w", err)
+		}
+		return nil
+	}
+
+	s.LGTM.Network = s.network
+	if s.LGTM.Network == nil {
+		var err error
+		s.LGTM.Network, err = network.New(ctx)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("otelstack: could not create new network: %w", err)
+		}
+		shutdownFuncs = append(shutdownFuncs, s.LGTM.Network.Remove)
+	}
+
+	lgtmShutdown, err := s.LGTM.Start(ctx)
+	if err != nil {
+		return emptyFunc, errors.Join(fmt.Errorf("otelstack: could not start lgtm: %w", err), shutdown(ctx))
+	}
+	shutdownFuncs = append(shutdownFuncs, lgtmShutdown)
+
+	host := s.LGTM.Host
+	s.Collector.Host = host
+	s.Collector.Ports = map[int]nat.Port{4317: s.LGTM.Ports[4317], 4318: s.LGTM.Ports[4318]}
+	s.Tempo = &tempo.Tempo{Host: host, Ports: map[int]nat.Port{3200: s.LGTM.Ports[3200]}}
+	s.Loki = &loki.Loki{Host: host, Ports: map[int]nat.Port{3100: s.LGTM.Ports[3100]}}
+	s.lgtmBackends = true
+	s.Prometheus.Host = host
+	s.Prometheus.Ports = map[int]nat.Port{9090: s.LGTM.Ports[9090]}
+
+	if s.Proxy != nil {
+		s.Proxy.GRPCTarget = strings.TrimPrefix(s.Collector.Endpoint(4317), "http://")
+		s.Proxy.HTTPTarget = s.Collector.Endpoint(4318)
+		proxyShutdown, err := s.Proxy.Start(ctx)
+		if err != nil {
+			return emptyFunc, errors.Join(fmt.Errorf("otelstack: could not start the fault proxy: %w", err), shutdown(ctx))
+		}
+		shutdownFuncs = append(shutdownFuncs, proxyShutdown)
+	}
+
+	return shutdown, nil
+}
@@ -0,0 +1,123 @@
+// Package lgtm holds the resources needed to start the all-in-one grafana/otel-lgtm testcontainer, which runs
+// a collector, Grafana, Loki, Tempo and Prometheus in a single container.
+package lgtm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/registry"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// DefaultImage is the image used for the LGTM container.
+const DefaultImage = "grafana/otel-lgtm:0.8.1"
+
+// DefaultAlias is the network alias that other containers use to reach the LGTM container.
+const DefaultAlias = "lgtm"
+
+// ports are the container ports of Grafana, Loki, Tempo, the collector's OTLP receivers and Prometheus.
+var ports = []int{3000, 3100, 3200, 4317, 4318, 9090}
+
+// LGTM hold the testcontainer, ports and network used by the grafana/otel-lgtm container. If instantiating yourself,
+// be sure to populate LGTM.Network, otherwise a new network will be generated.
+// Grafana is on port 3000, Loki on port 3100, Tempo on port 3200 and Prometheus on port 9090, and the collector
+// receives OTLP on ports 4317 and 4318.
+// Host is the hostname that the ports are reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
+type LGTM struct {
+	Ports    map[int]nat.Port
+	Network  *testcontainers.DockerNetwork
+	Host     string
+	Image    string
+	Registry *registry.Config
+	Alias    string
+	Name     string
+}
+
+// Start starts the LGTM container.
+func (l *LGTM) Start(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+	var err error
+
+	l.Ports = make(map[int]nat.Port)
+
+	if l.Network == nil {
+		l.Network, err = network.New(ctx)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("lgtm: network not provided and could not create a new one: %w", err)
+		}
+	}
+
+	if l.Alias == "" {
+		l.Alias = DefaultAlias
+	}
+
+	exposed := make([]string, 0, len(ports))
+	for _, portNum := range ports {
+		exposed = append(exposed, fmt.Sprintf("%d/tcp", portNum))
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          l.image(),
+		ExposedPorts:   exposed,
+		Networks:       []string{l.Network.Name},
+		NetworkAliases: map[string][]string{l.Network.Name: {l.Alias}},
+		// the image logs this once every service inside it is ready
+		WaitingFor: wait.ForLog("The OpenTelemetry collector and the Grafana LGTM stack are up and running").
+			WithStartupTimeout(time.Minute * 3),
+	}
+	if err := l.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("lgtm: could not prepare the image: %w", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return emptyFunc, fmt.Errorf("lgtm: could not start the testcontainer: %w", err)
+	}
+
+	l.Name, err = container.Name(ctx)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("lgtm: could not read the name of the container from the testcontainer: %w", err)
+	}
+	l.Name = strings.TrimPrefix(l.Name, "/")
+
+	for _, portNum := range ports {
+		l.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
+		if err != nil {
+			return emptyFunc, fmt.Errorf("lgtm: could not retrieve port %d from the testcontainer: %w", portNum, err)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
+	}, nil
+}
+
+// Endpoint returns the URL that the given container port of the LGTM container can be reached on.
+func (l *LGTM) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", l.host(), l.Ports[port].Int())
+}
+
+func (l *LGTM) host() string {
+	if l.Host == "" {
+		return "localhost"
+	}
+	return l.Host
+}
+
+func (l *LGTM) image() string {
+	if l.Image == "" {
+		return DefaultImage
+	}
+	return l.Image
+}
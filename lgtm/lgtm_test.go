@@ -0,0 +1,36 @@
+package lgtm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLGTMStart(t *testing.T) {
+	t.Parallel()
+	l := LGTM{}
+	shutdownFunc, err := l.Start(t.Context())
+	require.NoError(t, err, "lgtm must be able to start")
+	t.Cleanup(func() {
+		if err := shutdownFunc(context.Background()); err != nil {
+			t.Logf("error shutting down lgtm: %v", err)
+		}
+	})
+
+	for _, endpoint := range []string{
+		fmt.Sprintf("http://localhost:%d/api/health", l.Ports[3000].Int()),
+		fmt.Sprintf("http://localhost:%d/ready", l.Ports[3100].Int()),
+		fmt.Sprintf("http://localhost:%d/ready", l.Ports[3200].Int()),
+		fmt.Sprintf("http://localhost:%d/-/ready", l.Ports[9090].Int()),
+	} {
+		t.Logf("using endpoint: %s", endpoint)
+
+		resp, err := http.Get(endpoint)
+		require.NoError(t, err, "must be able to call lgtm")
+		assert.Equal(t, 200, resp.StatusCode, "request should be 200")
+	}
+}
@@ -0,0 +1,160 @@
+// Package loki holds the resources needed to start a Grafana Loki testcontainer, and to query it with LogQL.
+package loki
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/registry"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// DefaultImage is the image used for the Loki container.
+const DefaultImage = "grafana/loki:3.4.2"
+
+// DefaultAlias is the network alias that other containers use to reach Loki.
+const DefaultAlias = "loki"
+
+// Loki hold the testcontainer, ports and network used by Loki. If instantiating yourself,
+// be sure to populate Loki.Network, otherwise a new network will be generated.
+// The HTTP API (LogQL queries and OTLP ingestion under /otlp) is on port 3100.
+// Host is the hostname that the port is reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
+type Loki struct {
+	Ports    map[int]nat.Port
+	Network  *testcontainers.DockerNetwork
+	Host     string
+	Image    string
+	Registry *registry.Config
+	Alias    string
+	Name     string
+}
+
+// Start starts the Loki container.
+func (l *Loki) Start(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+	var err error
+
+	l.Ports = make(map[int]nat.Port)
+
+	if l.Network == nil {
+		l.Network, err = network.New(ctx)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("loki: network not provided and could not create a new one: %w", err)
+		}
+	}
+
+	if l.Alias == "" {
+		l.Alias = DefaultAlias
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          l.image(),
+		ExposedPorts:   []string{"3100/tcp"},
+		Networks:       []string{l.Network.Name},
+		NetworkAliases: map[string][]string{l.Network.Name: {l.Alias}},
+		// the ingester only reports ready after it has joined the ring
+		WaitingFor: wait.ForHTTP("/ready").WithPort("3100/tcp").WithStartupTimeout(time.Minute * 2),
+		Cmd:        []string{"-config.file=/etc/loki/config.yaml"},
+		Files: []testcontainers.ContainerFile{{
+			ContainerFilePath: "/etc/loki/config.yaml",
+			Reader:            strings.NewReader(config),
+			FileMode:          0644,
+		}},
+	}
+	if err := l.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("loki: could not prepare the image: %w", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return emptyFunc, fmt.Errorf("loki: could not start the testcontainer: %w", err)
+	}
+
+	l.Name, err = container.Name(ctx)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("loki: could not read the name of the container from the testcontainer: %w", err)
+	}
+	l.Name = strings.TrimPrefix(l.Name, "/")
+
+	for _, portNum := range []int{3100} {
+		l.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
+		if err != nil {
+			return emptyFunc, fmt.Errorf("loki: could not retrieve port %d from the testcontainer: %w", portNum, err)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
+	}, nil
+}
+
+// Config returns the Loki configuration used by the container.
+func (l *Loki) Config() string {
+	return config
+}
+
+var config = `auth_enabled: false
+
+server:
+  http_listen_port: 3100
+  log_level: warn
+
+common:
+  instance_addr: 127.0.0.1
+  path_prefix: /tmp/loki
+  storage:
+    filesystem:
+      chunks_directory: /tmp/loki/chunks
+      rules_directory: /tmp/loki/rules
+  replication_factor: 1
+  ring:
+    kvstore:
+      store: inmemory
+
+schema_config:
+  configs:
+    - from: 2024-01-01
+      store: tsdb
+      object_store: filesystem
+      schema: v13
+      index:
+        prefix: index_
+        period: 24h
+
+limits_config:
+  allow_structured_metadata: true
+`
+
+// OTLPEndpoint returns the URL that the collector's otlphttp exporter sends logs to on the stack network.
+func (l *Loki) OTLPEndpoint() string {
+	return fmt.Sprintf("http://%s:3100/otlp", l.Alias)
+}
+
+// Endpoint returns the URL that the given container port of Loki can be reached on.
+func (l *Loki) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", l.host(), l.Ports[port].Int())
+}
+
+func (l *Loki) host() string {
+	if l.Host == "" {
+		return "localhost"
+	}
+	return l.Host
+}
+
+func (l *Loki) image() string {
+	if l.Image == "" {
+		return DefaultImage
+	}
+	return l.Image
+}
@@ -0,0 +1,30 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLokiStart(t *testing.T) {
+	t.Parallel()
+	l := Loki{}
+	shutdownFunc, err := l.Start(t.Context())
+	require.NoError(t, err, "loki must be able to start")
+	t.Cleanup(func() {
+		if err := shutdownFunc(context.Background()); err != nil {
+			t.Logf("error shutting down loki: %v", err)
+		}
+	})
+
+	endpoint := fmt.Sprintf("http://localhost:%d/ready", l.Ports[3100].Int())
+	t.Logf("using endpoint: %s", endpoint)
+
+	resp, err := http.Get(endpoint)
+	require.NoError(t, err, "must be able to call loki")
+	assert.Equal(t, 200, resp.StatusCode, "request should be 200")
+}
@@ -0,0 +1,185 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/adreasnow/otelstack/request"
+)
+
+var _ backend.LogBackend = (*Loki)(nil)
+
+// queryLimit is the most log lines that QueryRange returns.
+const queryLimit = 1000
+
+// Stream is a set of log entries that share the same indexed labels. Loki stores the OTLP resource attributes
+// that it indexes (e.g. service.name) as labels, with the dots replaced by underscores (e.g. service_name).
+type Stream struct {
+	Labels  map[string]string
+	Entries []Entry
+}
+
+// Entry is a single log line. StructuredMetadata holds the non-indexed labels stored with the line, which
+// includes the OTLP log attributes, trace_id, span_id and severity_text. Parsed holds the labels extracted by
+// parser stages of the query (e.g. | json).
+type Entry struct {
+	Time               time.Time
+	Line               string
+	StructuredMetadata map[string]string
+	Parsed             map[string]string
+}
+
+type queryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Stream map[string]string   `json:"stream"`
+			Values [][]json.RawMessage `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+type categorizedLabels struct {
+	StructuredMetadata map[string]string `json:"structuredMetadata"`
+	Parsed             map[string]string `json:"parsed"`
+}
+
+// QueryRange runs a LogQL log query (e.g. `{service_name="api"} | severity_text="ERROR"`) between start and end,
+// and returns the matching streams with their entries most recent first.
+// Metric queries (e.g. count_over_time) return matrices rather than streams, and are rejected.
+func (l *Loki) QueryRange(ctx context.Context, logql string, start time.Time, end time.Time) ([]Stream, error) {
+	endpoint := fmt.Sprintf("%s/loki/api/v1/query_range?query=%s&start=%d&end=%d&limit=%d&direction=backward",
+		l.Endpoint(3100), url.QueryEscape(logql), start.UnixNano(), end.UnixNano(), queryLimit)
+
+	// categorised, structured metadata is returned alongside each line rather than merged into the stream labels
+	body, err := request.Get(ctx, endpoint, http.Header{"X-Loki-Response-Encoding-Flags": {"categorize-labels"}})
+	if err != nil {
+		return nil, fmt.Errorf("loki: could not query logs: %w", err)
+	}
+
+	var u queryResponse
+	if err := json.Unmarshal(body, &u); err != nil {
+		return nil, fmt.Errorf("loki: could not unmarshal response body %s: %w", string(body), err)
+	}
+	if u.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("loki: query returned %q rather than streams", u.Data.ResultType)
+	}
+
+	streams := make([]Stream, 0, len(u.Data.Result))
+	for _, result := range u.Data.Result {
+		stream := Stream{Labels: result.Stream}
+		for _, v := range result.Values {
+			entry, err := decodeEntry(v)
+			if err != nil {
+				return streams, err
+			}
+			stream.Entries = append(stream.Entries, entry)
+		}
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+// decodeEntry decodes a [timestamp, line] or [timestamp, line, categorized labels] value.
+func decodeEntry(v []json.RawMessage) (Entry, error) {
+	if len(v) < 2 {
+		return Entry{}, fmt.Errorf("loki: malformed entry %s", v)
+	}
+
+	var timestamp string
+	if err := json.Unmarshal(v[0], &timestamp); err != nil {
+		return Entry{}, fmt.Errorf("loki: malformed entry timestamp %s: %w", v[0], err)
+	}
+	nanos, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Entry{}, fmt.Errorf("loki: malformed entry timestamp %s: %w", timestamp, err)
+	}
+
+	entry := Entry{Time: time.Unix(0, nanos)}
+	if err := json.Unmarshal(v[1], &entry.Line); err != nil {
+		return Entry{}, fmt.Errorf("loki: malformed entry line %s: %w", v[1], err)
+	}
+
+	if len(v) > 2 {
+		var labels categorizedLabels
+		if err := json.Unmarshal(v[2], &labels); err != nil {
+			return Entry{}, fmt.Errorf("loki: malformed entry labels %s: %w", v[2], err)
+		}
+		entry.StructuredMetadata = labels.StructuredMetadata
+		entry.Parsed = labels.Parsed
+	}
+	return entry, nil
+}
+
+// LogRecords implements backend.LogBackend by querying the logs of every service received in the last hour.
+// Loki does not keep the dotted names of attributes, so the attributes and resource are keyed by the
+// underscored names that Loki stores them as.
+func (l *Loki) LogRecords(expectedRecords int, maxRetries int) ([]backend.LogRecord, error) {
+	var attempts int
+	for {
+		attempts++
+		if attempts > 1 {
+			time.Sleep(time.Second * 2)
+		}
+
+		end := time.Now()
+		streams, err := l.QueryRange(context.Background(), `{service_name=~".+"}`, end.Add(-time.Hour), end)
+		if err != nil && !errors.Is(err, request.ErrRetryableCode) {
+			return nil, err
+		}
+
+		records := neutralLogRecords(streams)
+		if len(records) >= expectedRecords {
+			return records, nil
+		}
+
+		if attempts >= maxRetries {
+			return records, fmt.Errorf("loki: could not get %d log records in %d attempts", expectedRecords, maxRetries)
+		}
+	}
+}
+
+func neutralLogRecords(streams []Stream) []backend.LogRecord {
+	var records []backend.LogRecord
+	for _, s := range streams {
+		resource := make(map[string]any, len(s.Labels))
+		for k, v := range s.Labels {
+			resource[k] = v
+		}
+
+		for _, e := range s.Entries {
+			record := backend.LogRecord{
+				Time:       e.Time,
+				Body:       e.Line,
+				Level:      e.StructuredMetadata["severity_text"],
+				TraceID:    e.StructuredMetadata["trace_id"],
+				SpanID:     e.StructuredMetadata["span_id"],
+				Attributes: map[string]any{},
+				Resource:   resource,
+			}
+			for k, v := range e.StructuredMetadata {
+				switch k {
+				case "severity_text", "severity_number", "trace_id", "span_id":
+				default:
+					record.Attributes[k] = v
+				}
+			}
+			records = append(records, record)
+		}
+	}
+
+	// each stream is sorted, but the streams are not sorted between each other
+	slices.SortStableFunc(records, func(a, b backend.LogRecord) int {
+		return b.Time.Compare(a.Time)
+	})
+	return records
+}
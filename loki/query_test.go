@@ -0,0 +1,94 @@
+package loki
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(t *testing.T, resultType string) Loki {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/query_range" || r.URL.Query().Get("start") == "" ||
+			r.Header.Get("X-Loki-Response-Encoding-Flags") != "categorize-labels" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if resultType != "streams" {
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"` + resultType + `","result":[]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"service_name":"api"},"values":[
+				["1700000000000000300","slow query",{"structuredMetadata":{"severity_text":"WARN","trace_id":"0a","span_id":"01","db_rows":"3"}}],
+				["1700000000000000100","started"]
+			]},
+			{"stream":{"service_name":"db"},"values":[
+				["1700000000000000200","connected",{"structuredMetadata":{"severity_text":"INFO"},"parsed":{"level":"info"}}]
+			]}
+		]}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	return Loki{Host: "127.0.0.1", Ports: map[int]nat.Port{3100: nat.Port(port)}}
+}
+
+func TestQueryRange(t *testing.T) {
+	t.Parallel()
+	l := testServer(t, "streams")
+
+	end := time.Now()
+	streams, err := l.QueryRange(t.Context(), `{service_name=~".+"}`, end.Add(-time.Hour), end)
+	require.NoError(t, err, "must be able to query the logs")
+	require.Len(t, streams, 2)
+	assert.Equal(t, Stream{
+		Labels: map[string]string{"service_name": "api"},
+		Entries: []Entry{
+			{
+				Time:               time.Unix(0, 1700000000000000300),
+				Line:               "slow query",
+				StructuredMetadata: map[string]string{"severity_text": "WARN", "trace_id": "0a", "span_id": "01", "db_rows": "3"},
+			},
+			{Time: time.Unix(0, 1700000000000000100), Line: "started"},
+		},
+	}, streams[0])
+	assert.Equal(t, map[string]string{"level": "info"}, streams[1].Entries[0].Parsed)
+
+	l = testServer(t, "matrix")
+	_, err = l.QueryRange(t.Context(), `count_over_time({service_name="api"}[1m])`, end.Add(-time.Hour), end)
+	require.Error(t, err, "must reject metric queries")
+}
+
+func TestLogRecords(t *testing.T) {
+	t.Parallel()
+	l := testServer(t, "streams")
+
+	records, err := l.LogRecords(3, 1)
+	require.NoError(t, err, "must be able to get the log records")
+	require.Len(t, records, 3)
+	assert.Equal(t, backend.LogRecord{
+		Time:       time.Unix(0, 1700000000000000300),
+		Body:       "slow query",
+		Level:      "WARN",
+		TraceID:    "0a",
+		SpanID:     "01",
+		Attributes: map[string]any{"db_rows": "3"},
+		Resource:   map[string]any{"service_name": "api"},
+	}, records[0])
+	assert.Equal(t, "connected", records[1].Body, "records must be sorted across streams")
+	assert.Equal(t, "started", records[2].Body)
+
+	_, err = l.LogRecords(4, 1)
+	require.Error(t, err, "must fail when the expected records are not returned")
+}
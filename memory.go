@@ -0,0 +1,110 @@
+package otelstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/adreasnow/otelstack/collector"
+	"github.com/adreasnow/otelstack/memory"
+	"github.com/docker/go-connections/nat"
+)
+
+// WithInMemory runs the stack in-process instead of starting containers: a pure-Go OTLP receiver stores every
+// signal in memory and answers the Jaeger, Seq and Prometheus queries, so that Jaeger.GetTraces, Seq.GetEvents
+// and Prometheus.GetMetrics work unchanged without Docker (see memory.Backend). It takes precedence over
+// external mode, and starting fails when any of the collector's own features are set (see Collector), as there
+// is no collector to apply them. Stats are not available either.
+func WithInMemory() Option {
+	return func(s *Stack) {
+		s.Memory = &memory.Backend{}
+	}
+}
+
+func (s *Stack) startMemory(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+
+	if feature, ok := collectorFeature(&s.Collector); ok {
+		return emptyFunc, fmt.Errorf("otelstack: the in-memory backend does not support the collector's %s", feature)
+	}
+	if s.Tempo != nil || s.Zipkin != nil || s.Loki != nil || s.Aspire != nil {
+		return emptyFunc, errors.New("otelstack: the in-memory backend does not support Tempo, Zipkin, Loki or Aspire")
+	}
+	if s.Prometheus.OTLP != nil || s.VictoriaMetrics != nil {
+		return emptyFunc, errors.New("otelstack: the in-memory backend does not support Prometheus OTLP ingestion or VictoriaMetrics")
+	}
+	if s.Grafana != nil {
+		return emptyFunc, errors.New("otelstack: the in-memory backend does not support Grafana")
+	}
+
+	shutdown, err := s.Memory.Start(ctx)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("otelstack: could not start the in-memory backend: %w", err)
+	}
+
+	host, grpcPort, err := net.SplitHostPort(s.Memory.GRPCAddr)
+	if err != nil {
+		return emptyFunc, errors.Join(fmt.Errorf("otelstack: invalid in-memory gRPC address: %w", err), shutdown(ctx))
+	}
+	_, httpPort, err := net.SplitHostPort(s.Memory.HTTPAddr)
+	if err != nil {
+		return emptyFunc, errors.Join(fmt.Errorf("otelstack: invalid in-memory HTTP address: %w", err), shutdown(ctx))
+	}
+	_, queryPort, err := net.SplitHostPort(s.Memory.QueryAddr)
+	if err != nil {
+		return emptyFunc, errors.Join(fmt.Errorf("otelstack: invalid in-memory query address: %w", err), shutdown(ctx))
+	}
+
+	s.Collector.Host = host
+	s.Collector.Ports = map[int]nat.Port{4317: nat.Port(grpcPort), 4318: nat.Port(httpPort)}
+	s.Jaeger.Host = host
+	s.Jaeger.Ports = map[int]nat.Port{16686: nat.Port(queryPort)}
+	s.Seq.Host = host
+	s.Seq.Ports = map[int]nat.Port{80: nat.Port(queryPort)}
+	s.Prometheus.Host = host
+	s.Prometheus.Ports = map[int]nat.Port{9090: nat.Port(queryPort)}
+
+	if s.Proxy != nil {
+		s.Proxy.GRPCTarget = s.Memory.GRPCAddr
+		s.Proxy.HTTPTarget = s.Memory.HTTPEndpoint()
+		proxyShutdown, err := s.Proxy.Start(ctx)
+		if err != nil {
+			return emptyFunc, errors.Join(fmt.Errorf("otelstack: could not start the fault proxy: %w", err), shutdown(ctx))
+		}
+		return func(ctx context.Context) error {
+			return errors.Join(proxyShutdown(ctx), shutdown(ctx))
+		}, nil
+	}
+
+	return shutdown, nil
+}
+
+// collectorFeature returns the first of the collector's features that is set, which the modes that don't run the
+// generated collector config cannot honour.
+func collectorFeature(c *collector.Collector) (string, bool) {
+	switch {
+	case c.TLS != nil:
+		return "TLS", true
+	case c.Auth != nil:
+		return "authentication", true
+	case len(c.MetadataHeaders) > 0:
+		return "metadata headers", true
+	case len(c.Processors) > 0:
+		return "processors", true
+	case len(c.Overlays) > 0:
+		return "config overlays", true
+	case len(c.Exporters) > 0 || len(c.ExtraExporters) > 0:
+		return "exporters", true
+	case c.SpanMetrics != nil:
+		return "span metrics", true
+	case c.ServiceGraph != nil:
+		return "service graph", true
+	case c.Capture:
+		return "capture", true
+	case c.ZipkinReceiver:
+		return "zipkin receiver", true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"cmp"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/adreasnow/otelstack/backend"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+type jaegerResponse struct {
+	Data   []jaegerTrace `json:"data"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+	Errors any           `json:"errors"`
+}
+
+type jaegerTrace struct {
+	TraceID   string                   `json:"traceID"`
+	Spans     []jaegerSpan             `json:"spans"`
+	Processes map[string]jaegerProcess `json:"processes"`
+	Warnings  any                      `json:"warnings"`
+}
+
+type jaegerProcess struct {
+	ServiceName string           `json:"serviceName"`
+	Tags        []jaegerKeyValue `json:"tags"`
+}
+
+type jaegerSpan struct {
+	TraceID       string            `json:"traceID"`
+	SpanID        string            `json:"spanID"`
+	OperationName string            `json:"operationName"`
+	References    []jaegerReference `json:"references"`
+	StartTime     int64             `json:"startTime"`
+	Duration      int64             `json:"duration"`
+	Tags          []jaegerKeyValue  `json:"tags"`
+	Logs          []jaegerLog       `json:"logs"`
+	ProcessID     string            `json:"processID"`
+	Warnings      any               `json:"warnings"`
+}
+
+type jaegerKeyValue struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+type jaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type jaegerLog struct {
+	Timestamp int64            `json:"timestamp"`
+	Fields    []jaegerKeyValue `json:"fields"`
+}
+
+// handleTraces answers /api/traces?service=<name>&limit=<n> with the most recent traces that include a span of the
+// service, translated the way Jaeger translates OTLP spans.
+func (b *Backend) handleTraces(w http.ResponseWriter, r *http.Request) {
+	service := r.URL.Query().Get("service")
+	if service == "" {
+		http.Error(w, "parameter 'service' is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid limit %q: %v", l, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resourceSpans, _, _ := b.snapshot()
+	traces := jaegerTraces(resourceSpans, service)
+	if limit > 0 && len(traces) > limit {
+		traces = traces[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jaegerResponse{Data: traces, Total: len(traces), Limit: limit}) //nolint:errcheck
+}
+
+// jaegerTraces groups the spans into traces, and returns the traces that include a span of the service,
+// most recently started first.
+func jaegerTraces(resourceSpans []*tracepb.ResourceSpans, service string) []jaegerTrace {
+	type grouped struct {
+		trace    jaegerTrace
+		matches  bool
+		lastSeen int64
+		process  map[*tracepb.ResourceSpans]string
+	}
+	var order []string
+	traces := map[string]*grouped{}
+
+	for _, rs := range resourceSpans {
+		name := serviceName(rs.GetResource().GetAttributes())
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				traceID := hex.EncodeToString(s.GetTraceId())
+				g, ok := traces[traceID]
+				if !ok {
+					g = &grouped{
+						trace:   jaegerTrace{TraceID: traceID, Processes: map[string]jaegerProcess{}},
+						process: map[*tracepb.ResourceSpans]string{},
+					}
+					traces[traceID] = g
+					order = append(order, traceID)
+				}
+
+				processID, ok := g.process[rs]
+				if !ok {
+					processID = "p" + strconv.Itoa(len(g.process)+1)
+					g.process[rs] = processID
+					g.trace.Processes[processID] = jaegerProcess{
+						ServiceName: name,
+						Tags:        jaegerTags(rs.GetResource().GetAttributes(), "service.name"),
+					}
+				}
+
+				span := jaegerSpanOf(s, ss.GetScope())
+				span.ProcessID = processID
+				g.trace.Spans = append(g.trace.Spans, span)
+				g.matches = g.matches || name == service
+				g.lastSeen = max(g.lastSeen, span.StartTime)
+			}
+		}
+	}
+
+	var matched []*grouped
+	for _, traceID := range order {
+		if traces[traceID].matches {
+			matched = append(matched, traces[traceID])
+		}
+	}
+	slices.SortStableFunc(matched, func(a *grouped, b *grouped) int {
+		return cmp.Compare(b.lastSeen, a.lastSeen)
+	})
+
+	out := make([]jaegerTrace, 0, len(matched))
+	for _, g := range matched {
+		out = append(out, g.trace)
+	}
+	return out
+}
+
+func jaegerSpanOf(s *tracepb.Span, scope *commonpb.InstrumentationScope) jaegerSpan {
+	traceID := hex.EncodeToString(s.GetTraceId())
+	span := jaegerSpan{
+		TraceID:       traceID,
+		SpanID:        hex.EncodeToString(s.GetSpanId()),
+		OperationName: s.GetName(),
+		References:    []jaegerReference{},
+		StartTime:     int64(s.GetStartTimeUnixNano() / 1000),
+		Duration:      int64((s.GetEndTimeUnixNano() - s.GetStartTimeUnixNano()) / 1000),
+		Tags:          jaegerTags(s.GetAttributes()),
+		Logs:          []jaegerLog{},
+	}
+
+	if len(s.GetParentSpanId()) > 0 {
+		span.References = append(span.References, jaegerReference{
+			RefType: "CHILD_OF", TraceID: traceID, SpanID: hex.EncodeToString(s.GetParentSpanId()),
+		})
+	}
+	for _, l := range s.GetLinks() {
+		span.References = append(span.References, jaegerReference{
+			RefType: "FOLLOWS_FROM", TraceID: hex.EncodeToString(l.GetTraceId()), SpanID: hex.EncodeToString(l.GetSpanId()),
+		})
+	}
+
+	if s.GetKind() != tracepb.Span_SPAN_KIND_UNSPECIFIED {
+		kind := strings.ToLower(strings.TrimPrefix(s.GetKind().String(), "SPAN_KIND_"))
+		span.Tags = append(span.Tags, jaegerKeyValue{Key: "span.kind", Type: "string", Value: kind})
+	}
+	if scope.GetName() != "" {
+		span.Tags = append(span.Tags, jaegerKeyValue{Key: "otel.scope.name", Type: "string", Value: scope.GetName()})
+	}
+	if scope.GetVersion() != "" {
+		span.Tags = append(span.Tags, jaegerKeyValue{Key: "otel.scope.version", Type: "string", Value: scope.GetVersion()})
+	}
+	switch s.GetStatus().GetCode() {
+	case tracepb.Status_STATUS_CODE_OK:
+		span.Tags = append(span.Tags, jaegerKeyValue{Key: "otel.status_code", Type: "string", Value: "OK"})
+	case tracepb.Status_STATUS_CODE_ERROR:
+		span.Tags = append(span.Tags,
+			jaegerKeyValue{Key: "otel.status_code", Type: "string", Value: "ERROR"},
+			jaegerKeyValue{Key: "error", Type: "bool", Value: true},
+		)
+	}
+	if s.GetStatus().GetMessage() != "" {
+		span.Tags = append(span.Tags, jaegerKeyValue{Key: "otel.status_description", Type: "string", Value: s.GetStatus().GetMessage()})
+	}
+
+	for _, e := range s.GetEvents() {
+		span.Logs = append(span.Logs, jaegerLog{
+			Timestamp: int64(e.GetTimeUnixNano() / 1000),
+			Fields:    append([]jaegerKeyValue{{Key: "event", Type: "string", Value: e.GetName()}}, jaegerTags(e.GetAttributes())...),
+		})
+	}
+
+	return span
+}
+
+// jaegerTags converts attributes to Jaeger tags, skipping the excluded keys. Arrays and maps are encoded as JSON strings.
+func jaegerTags(attributes []*commonpb.KeyValue, exclude ...string) []jaegerKeyValue {
+	tags := []jaegerKeyValue{}
+	for _, kv := range attributes {
+		if slices.Contains(exclude, kv.GetKey()) {
+			continue
+		}
+		switch v := backend.ValueFromOTLP(kv.GetValue()).(type) {
+		case bool:
+			tags = append(tags, jaegerKeyValue{Key: kv.GetKey(), Type: "bool", Value: v})
+		case int64:
+			tags = append(tags, jaegerKeyValue{Key: kv.GetKey(), Type: "int64", Value: v})
+		case float64:
+			tags = append(tags, jaegerKeyValue{Key: kv.GetKey(), Type: "float64", Value: v})
+		case []byte:
+			tags = append(tags, jaegerKeyValue{Key: kv.GetKey(), Type: "binary", Value: hex.EncodeToString(v)})
+		default:
+			tags = append(tags, jaegerKeyValue{Key: kv.GetKey(), Type: "string", Value: stringValue(kv.GetValue())})
+		}
+	}
+	return tags
+}
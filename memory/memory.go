@@ -0,0 +1,277 @@
+// Package memory holds an in-process OTLP backend that stores telemetry in memory and serves the query APIs
+// used by the jaeger, seq and prometheus packages, so that tests can run without Docker.
+package memory
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor used by the OTLP exporters
+	"google.golang.org/protobuf/proto"
+
+	"github.com/adreasnow/otelstack/backend"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Backend receives OTLP over gRPC and HTTP, and stores every signal in memory. The query server answers the subset
+// of the Jaeger (/api/traces), Seq (/api/events) and Prometheus (/api/v1/query and /api/v1/query_range) APIs that
+// the query helpers use, so that jaeger.Jaeger, seq.Seq and prometheus.Prometheus can be pointed at QueryAddr.
+//
+// The HTTP receiver only accepts protobuf encoded requests, and the Prometheus API only evaluates series selectors
+// (e.g. goroutine_count{service_name="api"}), with metric names translated the way the collector's prometheus
+// exporter does, apart from unit suffixes.
+//
+// GRPCAddr, HTTPAddr and QueryAddr are the addresses that the backend listens on, and are populated by Start.
+type Backend struct {
+	GRPCAddr  string
+	HTTPAddr  string
+	QueryAddr string
+
+	mu       sync.Mutex
+	spans    []*tracepb.ResourceSpans
+	logs     []*logspb.ResourceLogs
+	metrics  []*metricspb.ResourceMetrics
+	received int
+}
+
+// Start listens for OTLP and query requests on random local ports, and returns a function that stops the backend.
+func (b *Backend) Start(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+
+	var lc net.ListenConfig
+	grpcListener, err := lc.Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		return emptyFunc, fmt.Errorf("memory: could not listen for OTLP gRPC requests: %w", err)
+	}
+	httpListener, err := lc.Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		return emptyFunc, errors.Join(fmt.Errorf("memory: could not listen for OTLP HTTP requests: %w", err), grpcListener.Close())
+	}
+	queryListener, err := lc.Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		return emptyFunc, errors.Join(
+			fmt.Errorf("memory: could not listen for query requests: %w", err), grpcListener.Close(), httpListener.Close(),
+		)
+	}
+
+	grpcServer := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(grpcServer, traceService{backend: b})
+	collogspb.RegisterLogsServiceServer(grpcServer, logsService{backend: b})
+	colmetricspb.RegisterMetricsServiceServer(grpcServer, metricsService{backend: b})
+	go grpcServer.Serve(grpcListener) //nolint:errcheck
+
+	receiver := http.NewServeMux()
+	receiver.HandleFunc("POST /v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		receive(w, r, &coltracepb.ExportTraceServiceRequest{}, &coltracepb.ExportTraceServiceResponse{},
+			func(req *coltracepb.ExportTraceServiceRequest) { b.addSpans(req.GetResourceSpans()) })
+	})
+	receiver.HandleFunc("POST /v1/logs", func(w http.ResponseWriter, r *http.Request) {
+		receive(w, r, &collogspb.ExportLogsServiceRequest{}, &collogspb.ExportLogsServiceResponse{},
+			func(req *collogspb.ExportLogsServiceRequest) { b.addLogs(req.GetResourceLogs()) })
+	})
+	receiver.HandleFunc("POST /v1/metrics", func(w http.ResponseWriter, r *http.Request) {
+		receive(w, r, &colmetricspb.ExportMetricsServiceRequest{}, &colmetricspb.ExportMetricsServiceResponse{},
+			func(req *colmetricspb.ExportMetricsServiceRequest) { b.addMetrics(req.GetResourceMetrics()) })
+	})
+	httpServer := &http.Server{Handler: receiver, ReadHeaderTimeout: time.Second * 10}
+	go httpServer.Serve(httpListener) //nolint:errcheck
+
+	queryServer := &http.Server{Handler: b.queryHandler(), ReadHeaderTimeout: time.Second * 10}
+	go queryServer.Serve(queryListener) //nolint:errcheck
+
+	b.GRPCAddr = grpcListener.Addr().String()
+	b.HTTPAddr = httpListener.Addr().String()
+	b.QueryAddr = queryListener.Addr().String()
+
+	return func(ctx context.Context) error {
+		grpcServer.Stop()
+		return errors.Join(httpServer.Shutdown(ctx), queryServer.Shutdown(ctx))
+	}, nil
+}
+
+// GRPCEndpoint returns the URL that exporters can send OTLP gRPC requests to.
+func (b *Backend) GRPCEndpoint() string {
+	return "http://" + b.GRPCAddr
+}
+
+// HTTPEndpoint returns the URL that exporters can send OTLP HTTP requests to.
+func (b *Backend) HTTPEndpoint() string {
+	return "http://" + b.HTTPAddr
+}
+
+// Requests returns the number of export requests that have been received across all signals.
+func (b *Backend) Requests() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.received
+}
+
+// Reset removes all the stored telemetry.
+func (b *Backend) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spans = nil
+	b.logs = nil
+	b.metrics = nil
+	b.received = 0
+}
+
+func (b *Backend) addSpans(spans []*tracepb.ResourceSpans) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spans = append(b.spans, spans...)
+	b.received++
+}
+
+func (b *Backend) addLogs(logs []*logspb.ResourceLogs) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.logs = append(b.logs, logs...)
+	b.received++
+}
+
+func (b *Backend) addMetrics(metrics []*metricspb.ResourceMetrics) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.metrics = append(b.metrics, metrics...)
+	b.received++
+}
+
+// snapshot returns copies of the stored slices, whose elements are never modified once stored.
+func (b *Backend) snapshot() ([]*tracepb.ResourceSpans, []*logspb.ResourceLogs, []*metricspb.ResourceMetrics) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*tracepb.ResourceSpans(nil), b.spans...),
+		append([]*logspb.ResourceLogs(nil), b.logs...),
+		append([]*metricspb.ResourceMetrics(nil), b.metrics...)
+}
+
+type traceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+	backend *Backend
+}
+
+func (s traceService) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	s.backend.addSpans(req.GetResourceSpans())
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+type logsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	backend *Backend
+}
+
+func (s logsService) Export(_ context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	s.backend.addLogs(req.GetResourceLogs())
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+type metricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	backend *Backend
+}
+
+func (s metricsService) Export(_ context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	s.backend.addMetrics(req.GetResourceMetrics())
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+// receive decodes a protobuf OTLP HTTP request into req, stores it, and writes resp.
+func receive[Req proto.Message](w http.ResponseWriter, r *http.Request, req Req, resp proto.Message, store func(Req)) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-protobuf" {
+		http.Error(w, fmt.Sprintf("unsupported content type %q, only application/x-protobuf is accepted", mediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("could not decompress the body: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close() //nolint:errcheck
+		body = gz
+	}
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read the body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := proto.Unmarshal(raw, req); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode the body: %v", err), http.StatusBadRequest)
+		return
+	}
+	store(req)
+
+	out, err := proto.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(out) //nolint:errcheck
+}
+
+// queryHandler serves the query APIs, along with the paths that are probed to check that each service is up.
+func (b *Backend) queryHandler() http.Handler {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+	mux.HandleFunc("GET /{$}", ok)
+	mux.HandleFunc("GET /api", ok)
+	mux.HandleFunc("GET /-/ready", ok)
+	mux.HandleFunc("GET /api/traces", b.handleTraces)
+	mux.HandleFunc("GET /api/events", b.handleEvents)
+	mux.HandleFunc("GET /api/v1/query", b.handleQuery)
+	mux.HandleFunc("GET /api/v1/query_range", b.handleQueryRange)
+	return mux
+}
+
+// stringValue formats an attribute as a string, with arrays and maps encoded as JSON.
+func stringValue(v *commonpb.AnyValue) string {
+	switch v := backend.ValueFromOTLP(v).(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// serviceName returns the service.name attribute of a resource.
+func serviceName(attributes []*commonpb.KeyValue) string {
+	for _, kv := range attributes {
+		if kv.GetKey() == "service.name" {
+			return stringValue(kv.GetValue())
+		}
+	}
+	return ""
+}
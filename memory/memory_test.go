@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adreasnow/otelstack/jaeger"
+	"github.com/adreasnow/otelstack/prometheus"
+	"github.com/adreasnow/otelstack/seq"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const testService = "memory-test"
+
+func startBackend(t *testing.T) (*Backend, string, nat.Port) {
+	t.Helper()
+
+	b := &Backend{}
+	shutdown, err := b.Start(t.Context())
+	require.NoError(t, err, "the backend must start")
+	t.Cleanup(func() {
+		if err := shutdown(context.Background()); err != nil {
+			t.Logf("error shutting down the backend: %v", err)
+		}
+	})
+
+	host, port, err := net.SplitHostPort(b.QueryAddr)
+	require.NoError(t, err)
+	return b, host, nat.Port(port)
+}
+
+func TestBackend(t *testing.T) {
+	t.Parallel()
+	b, host, port := startBackend(t)
+	ctx := t.Context()
+	res := resource.NewSchemaless(attribute.String("service.name", testService))
+
+	{ // send spans over gRPC
+		exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(b.GRPCAddr), otlptracegrpc.WithInsecure())
+		require.NoError(t, err)
+		provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+
+		ctx, parent := provider.Tracer("memory").Start(ctx, "parent")
+		_, child := provider.Tracer("memory").Start(ctx, "child", trace.WithSpanKind(trace.SpanKindClient))
+		child.SetAttributes(attribute.Int("answer", 42))
+		child.End()
+		parent.End()
+		require.NoError(t, provider.Shutdown(ctx))
+	}
+
+	{ // send logs over HTTP
+		exporter, err := otlploghttp.New(ctx, otlploghttp.WithEndpoint(b.HTTPAddr), otlploghttp.WithInsecure())
+		require.NoError(t, err)
+		provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)), sdklog.WithResource(res))
+
+		record := log.Record{}
+		record.SetTimestamp(time.Now())
+		record.SetBody(log.StringValue("test message"))
+		record.SetSeverity(log.SeverityError)
+		record.AddAttributes(log.String("user.id", "u-1"))
+		provider.Logger("memory").Emit(ctx, record)
+		require.NoError(t, provider.Shutdown(ctx))
+	}
+
+	{ // send metrics over gRPC
+		exporter, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithEndpoint(b.GRPCAddr), otlpmetricgrpc.WithInsecure())
+		require.NoError(t, err)
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)), sdkmetric.WithResource(res))
+
+		counter, err := provider.Meter("memory").Int64Counter("requests")
+		require.NoError(t, err)
+		counter.Add(ctx, 3)
+		require.NoError(t, provider.Shutdown(ctx))
+	}
+
+	assert.Equal(t, 3, b.Requests())
+
+	j := jaeger.Jaeger{Host: host, Ports: map[int]nat.Port{16686: port}}
+	traces, _, err := j.GetTraces(1, 1, testService)
+	require.NoError(t, err, "must be able to get traces")
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0].Spans, 2)
+	assert.Equal(t, testService, traces[0].Processes.P1.ServiceName)
+	assert.Equal(t, "child", traces[0].Spans[0].OperationName)
+	assert.Equal(t, traces[0].Spans[1].SpanID, traces[0].Spans[0].References[0].SpanID, "the child must reference its parent")
+	assert.Contains(t, traces[0].Spans[0].Tags, jaeger.KeyValue{Key: "answer", Type: "int64", Value: float64(42)})
+	assert.Contains(t, traces[0].Spans[0].Tags, jaeger.KeyValue{Key: "span.kind", Type: "string", Value: "client"})
+
+	s := seq.Seq{Host: host, Ports: map[int]nat.Port{80: port}}
+	events, _, err := s.GetEvents(1, 1)
+	require.NoError(t, err, "must be able to get events")
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Messages, 1)
+	assert.Equal(t, "test message", events[0].Messages[0].Text)
+	assert.Equal(t, "Error", events[0].Level)
+	assert.Equal(t, testService, events[0].Resource[0].Value.Name)
+	assert.Equal(t, []seq.Property{{Name: "user", Value: map[string]any{"id": "u-1"}}}, events[0].Properties)
+
+	p := prometheus.Prometheus{Host: host, Ports: map[int]nat.Port{9090: port}}
+	metrics, _, err := p.GetMetrics(1, 10, "requests_total", testService, time.Minute)
+	require.NoError(t, err, "must be able to get metrics")
+	assert.Equal(t, testService, metrics.Metric["job"])
+	assert.Equal(t, "3", metrics.Values[len(metrics.Values)-1][1])
+
+	b.Reset()
+	assert.Zero(t, b.Requests())
+	traces, _, err = j.GetTraces(0, 1, testService)
+	require.NoError(t, err)
+	assert.Empty(t, traces)
+}
+
+func TestBackendRejectsJSON(t *testing.T) {
+	t.Parallel()
+	b, _, _ := startBackend(t)
+
+	resp, err := http.Post(b.HTTPEndpoint()+"/v1/traces", "application/json", bytes.NewBufferString(`{"resourceSpans":[]}`))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	assert.Zero(t, b.Requests())
+}
@@ -0,0 +1,445 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+// lookback is how far back an instant query looks for the latest sample of a series, as in Prometheus.
+const lookback = time.Minute * 5
+
+// maxPoints is the most points that a range query can return per series, as in Prometheus.
+const maxPoints = 11000
+
+type sample struct {
+	t time.Time
+	v float64
+}
+
+type series struct {
+	labels  map[string]string
+	samples []sample
+}
+
+// at returns the latest sample of the series at t, within the lookback window.
+func (s *series) at(t time.Time) (sample, bool) {
+	i, _ := slices.BinarySearchFunc(s.samples, t, func(s sample, t time.Time) int {
+		if s.t.After(t) {
+			return 1
+		}
+		return -1
+	})
+	if i == 0 || !s.samples[i-1].t.After(t.Add(-lookback)) {
+		return sample{}, false
+	}
+	return s.samples[i-1], true
+}
+
+type matcher struct {
+	name  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (m matcher) matches(labels map[string]string) bool {
+	v := labels[m.name]
+	switch m.op {
+	case "=":
+		return v == m.value
+	case "!=":
+		return v != m.value
+	case "=~":
+		return m.re.MatchString(v)
+	default:
+		return !m.re.MatchString(v)
+	}
+}
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`)
+	invalidNameChars  = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// parseSelector parses a series selector such as goroutine_count{service_name="api",le!="+Inf"}.
+// Functions, operators and range selectors are not supported.
+func parseSelector(query string) ([]matcher, error) {
+	query = strings.TrimSpace(query)
+	name, rest, _ := strings.Cut(query, "{")
+	name = strings.TrimSpace(name)
+
+	var matchers []matcher
+	if name != "" {
+		if !metricNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("unsupported query %q, only series selectors are supported", query)
+		}
+		matchers = append(matchers, matcher{name: "__name__", op: "=", value: name})
+	}
+
+	if !strings.Contains(query, "{") {
+		if name == "" {
+			return nil, fmt.Errorf("empty query")
+		}
+		return matchers, nil
+	}
+	if !strings.HasSuffix(rest, "}") {
+		return nil, fmt.Errorf("unsupported query %q, only series selectors are supported", query)
+	}
+	rest = strings.TrimSuffix(rest, "}")
+
+	for {
+		rest = strings.TrimLeft(rest, " ,")
+		if rest == "" {
+			break
+		}
+
+		label := labelNamePattern.FindString(rest)
+		if label == "" {
+			return nil, fmt.Errorf("invalid label matcher at %q", rest)
+		}
+		rest = strings.TrimSpace(rest[len(label):])
+
+		var op string
+		for _, o := range []string{"=~", "!~", "!=", "="} {
+			if strings.HasPrefix(rest, o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return nil, fmt.Errorf("invalid operator for label %s at %q", label, rest)
+		}
+		rest = strings.TrimSpace(rest[len(op):])
+
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for label %s at %q: %w", label, rest, err)
+		}
+		rest = rest[len(quoted):]
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for label %s: %w", label, err)
+		}
+
+		m := matcher{name: label, op: op, value: value}
+		if op == "=~" || op == "!~" {
+			m.re, err = regexp.Compile("^(?:" + value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression for label %s: %w", label, err)
+			}
+		}
+		matchers = append(matchers, m)
+	}
+
+	if len(matchers) == 0 {
+		return nil, fmt.Errorf("the selector %q must contain at least one matcher", query)
+	}
+	return matchers, nil
+}
+
+// selectSeries returns the series that match every matcher, ordered by their labels.
+func selectSeries(all []*series, matchers []matcher) []*series {
+	var selected []*series
+	for _, s := range all {
+		if !slices.ContainsFunc(matchers, func(m matcher) bool { return !m.matches(s.labels) }) {
+			selected = append(selected, s)
+		}
+	}
+	return selected
+}
+
+// promSeries converts the data points into series, named and labelled the way the collector's prometheus
+// exporter does with resource_to_telemetry_conversion enabled.
+func promSeries(resourceMetrics []*metricspb.ResourceMetrics) []*series {
+	index := map[string]*series{}
+
+	add := func(labels map[string]string, timestamp uint64, v float64) {
+		key := labelsKey(labels)
+		s, ok := index[key]
+		if !ok {
+			s = &series{labels: labels}
+			index[key] = s
+		}
+		s.samples = append(s.samples, sample{t: time.Unix(0, int64(timestamp)), v: v})
+	}
+
+	for _, rm := range resourceMetrics {
+		resource := map[string]string{}
+		setLabels(resource, rm.GetResource().GetAttributes())
+		if job := resource["service_name"]; job != "" {
+			if namespace := resource["service_namespace"]; namespace != "" {
+				job = namespace + "/" + job
+			}
+			resource["job"] = job
+		}
+		if instance := resource["service_instance_id"]; instance != "" {
+			resource["instance"] = instance
+		}
+
+		for _, sm := range rm.GetScopeMetrics() {
+			scope := maps.Clone(resource)
+			if sm.GetScope().GetName() != "" {
+				scope["otel_scope_name"] = sm.GetScope().GetName()
+			}
+			if sm.GetScope().GetVersion() != "" {
+				scope["otel_scope_version"] = sm.GetScope().GetVersion()
+			}
+
+			for _, m := range sm.GetMetrics() {
+				name := invalidNameChars.ReplaceAllString(m.GetName(), "_")
+				labels := func(name string, attributes []*commonpb.KeyValue, extra ...string) map[string]string {
+					l := maps.Clone(scope)
+					setLabels(l, attributes)
+					for i := 0; i+1 < len(extra); i += 2 {
+						l[extra[i]] = extra[i+1]
+					}
+					l["__name__"] = name
+					return l
+				}
+
+				switch m.GetData().(type) {
+				case *metricspb.Metric_Gauge:
+					for _, dp := range m.GetGauge().GetDataPoints() {
+						add(labels(name, dp.GetAttributes()), dp.GetTimeUnixNano(), numberValue(dp))
+					}
+				case *metricspb.Metric_Sum:
+					sumName := name
+					if m.GetSum().GetIsMonotonic() && !strings.HasSuffix(sumName, "_total") {
+						sumName += "_total"
+					}
+					for _, dp := range m.GetSum().GetDataPoints() {
+						add(labels(sumName, dp.GetAttributes()), dp.GetTimeUnixNano(), numberValue(dp))
+					}
+				case *metricspb.Metric_Histogram:
+					for _, dp := range m.GetHistogram().GetDataPoints() {
+						add(labels(name+"_count", dp.GetAttributes()), dp.GetTimeUnixNano(), float64(dp.GetCount()))
+						if dp.Sum != nil {
+							add(labels(name+"_sum", dp.GetAttributes()), dp.GetTimeUnixNano(), dp.GetSum())
+						}
+						var cumulative uint64
+						for i, count := range dp.GetBucketCounts() {
+							cumulative += count
+							le := "+Inf"
+							if i < len(dp.GetExplicitBounds()) {
+								le = strconv.FormatFloat(dp.GetExplicitBounds()[i], 'f', -1, 64)
+							}
+							add(labels(name+"_bucket", dp.GetAttributes(), "le", le), dp.GetTimeUnixNano(), float64(cumulative))
+						}
+					}
+				case *metricspb.Metric_ExponentialHistogram:
+					for _, dp := range m.GetExponentialHistogram().GetDataPoints() {
+						add(labels(name+"_count", dp.GetAttributes()), dp.GetTimeUnixNano(), float64(dp.GetCount()))
+						if dp.Sum != nil {
+							add(labels(name+"_sum", dp.GetAttributes()), dp.GetTimeUnixNano(), dp.GetSum())
+						}
+					}
+				case *metricspb.Metric_Summary:
+					for _, dp := range m.GetSummary().GetDataPoints() {
+						add(labels(name+"_count", dp.GetAttributes()), dp.GetTimeUnixNano(), float64(dp.GetCount()))
+						add(labels(name+"_sum", dp.GetAttributes()), dp.GetTimeUnixNano(), dp.GetSum())
+						for _, q := range dp.GetQuantileValues() {
+							quantile := strconv.FormatFloat(q.GetQuantile(), 'f', -1, 64)
+							add(labels(name, dp.GetAttributes(), "quantile", quantile), dp.GetTimeUnixNano(), q.GetValue())
+						}
+					}
+				}
+			}
+		}
+	}
+
+	all := make([]*series, 0, len(index))
+	for _, s := range index {
+		slices.SortStableFunc(s.samples, func(a sample, b sample) int { return a.t.Compare(b.t) })
+		all = append(all, s)
+	}
+	slices.SortFunc(all, func(a *series, b *series) int { return strings.Compare(labelsKey(a.labels), labelsKey(b.labels)) })
+	return all
+}
+
+func numberValue(dp *metricspb.NumberDataPoint) float64 {
+	if _, ok := dp.GetValue().(*metricspb.NumberDataPoint_AsInt); ok {
+		return float64(dp.GetAsInt())
+	}
+	return dp.GetAsDouble()
+}
+
+// setLabels sets the attributes as labels, with the characters that are invalid in label names replaced by underscores.
+func setLabels(labels map[string]string, attributes []*commonpb.KeyValue) {
+	for _, kv := range attributes {
+		name := invalidLabelChars.ReplaceAllString(kv.GetKey(), "_")
+		if name != "" && name[0] >= '0' && name[0] <= '9' {
+			name = "key_" + name
+		}
+		labels[name] = stringValue(kv.GetValue())
+	}
+}
+
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%q,", k, labels[k])
+	}
+	return b.String()
+}
+
+type promResponse struct {
+	Status    string   `json:"status"`
+	Data      promData `json:"data"`
+	ErrorType string   `json:"errorType,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type promData struct {
+	ResultType string `json:"resultType"`
+	Result     []any  `json:"result"`
+}
+
+type promVector struct {
+	Metric map[string]string `json:"metric"`
+	Value  []any             `json:"value"`
+}
+
+type promMatrix struct {
+	Metric map[string]string `json:"metric"`
+	Values [][]any           `json:"values"`
+}
+
+// handleQuery answers /api/v1/query?query=<selector>&time=<t> with the latest sample of each matching series.
+func (b *Backend) handleQuery(w http.ResponseWriter, r *http.Request) {
+	matchers, err := parseSelector(r.URL.Query().Get("query"))
+	if err != nil {
+		promError(w, err)
+		return
+	}
+
+	t := time.Now()
+	if param := r.URL.Query().Get("time"); param != "" {
+		if t, err = parseTime(param); err != nil {
+			promError(w, err)
+			return
+		}
+	}
+
+	_, _, resourceMetrics := b.snapshot()
+	result := []any{}
+	for _, s := range selectSeries(promSeries(resourceMetrics), matchers) {
+		if sample, ok := s.at(t); ok {
+			result = append(result, promVector{Metric: s.labels, Value: promSample(t, sample.v)})
+		}
+	}
+
+	promWrite(w, promData{ResultType: "vector", Result: result})
+}
+
+// handleQueryRange answers /api/v1/query_range?query=<selector>&start=<t>&end=<t>&step=<d> with the latest sample of
+// each matching series at every step.
+func (b *Backend) handleQueryRange(w http.ResponseWriter, r *http.Request) {
+	matchers, err := parseSelector(r.URL.Query().Get("query"))
+	if err != nil {
+		promError(w, err)
+		return
+	}
+
+	start, err := parseTime(r.URL.Query().Get("start"))
+	if err != nil {
+		promError(w, fmt.Errorf("invalid start: %w", err))
+		return
+	}
+	end, err := parseTime(r.URL.Query().Get("end"))
+	if err != nil {
+		promError(w, fmt.Errorf("invalid end: %w", err))
+		return
+	}
+	step, err := parseDuration(r.URL.Query().Get("step"))
+	if err != nil {
+		promError(w, fmt.Errorf("invalid step: %w", err))
+		return
+	}
+	if end.Before(start) {
+		promError(w, fmt.Errorf("end timestamp must not be before start time"))
+		return
+	}
+	if step <= 0 {
+		promError(w, fmt.Errorf("zero or negative query resolution step widths are not accepted"))
+		return
+	}
+	if end.Sub(start)/step > maxPoints {
+		promError(w, fmt.Errorf("exceeded maximum resolution of %d points per timeseries", maxPoints))
+		return
+	}
+
+	_, _, resourceMetrics := b.snapshot()
+	result := []any{}
+	for _, s := range selectSeries(promSeries(resourceMetrics), matchers) {
+		values := [][]any{}
+		for t := start; !t.After(end); t = t.Add(step) {
+			if sample, ok := s.at(t); ok {
+				values = append(values, promSample(t, sample.v))
+			}
+		}
+		if len(values) > 0 {
+			result = append(result, promMatrix{Metric: s.labels, Values: values})
+		}
+	}
+
+	promWrite(w, promData{ResultType: "matrix", Result: result})
+}
+
+func promSample(t time.Time, v float64) []any {
+	return []any{float64(t.UnixMilli()) / 1000, strconv.FormatFloat(v, 'f', -1, 64)}
+}
+
+func promWrite(w http.ResponseWriter, data promData) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(promResponse{Status: "success", Data: data}) //nolint:errcheck
+}
+
+func promError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(promResponse{Status: "error", ErrorType: "bad_data", Error: err.Error()}) //nolint:errcheck
+}
+
+// parseTime parses a timestamp given as RFC3339 or as (fractional) unix seconds.
+func parseTime(s string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*float64(time.Second))), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+	}
+	return t, nil
+}
+
+// parseDuration parses a duration given as a Go duration (10s) or as (fractional) seconds.
+func parseDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %q to a valid duration", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+func TestParseSelector(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		name     string
+		query    string
+		expected []matcher
+		err      string
+	}{
+		{
+			name:     "name only",
+			query:    "goroutine_count",
+			expected: []matcher{{name: "__name__", op: "=", value: "goroutine_count"}},
+		},
+		{
+			name:  "matchers",
+			query: `goroutine_count{service_name="api", le!="+Inf"}`,
+			expected: []matcher{
+				{name: "__name__", op: "=", value: "goroutine_count"},
+				{name: "service_name", op: "=", value: "api"},
+				{name: "le", op: "!=", value: "+Inf"},
+			},
+		},
+		{
+			name:     "matchers only",
+			query:    `{job="api"}`,
+			expected: []matcher{{name: "job", op: "=", value: "api"}},
+		},
+		{name: "function", query: `sum(goroutine_count)`, err: "only series selectors are supported"},
+		{name: "unquoted value", query: `goroutine_count{job=api}`, err: "invalid value for label job"},
+		{name: "empty", query: "", err: "empty query"},
+		{name: "empty selector", query: "{}", err: "at least one matcher"},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			matchers, err := parseSelector(tt.query)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matchers)
+		})
+	}
+
+	t.Run("regular expressions", func(t *testing.T) {
+		t.Parallel()
+		matchers, err := parseSelector(`{job=~"api|web", instance!~"canary-.*"}`)
+		require.NoError(t, err)
+		require.Len(t, matchers, 2)
+		assert.True(t, matchers[0].matches(map[string]string{"job": "web"}))
+		assert.False(t, matchers[0].matches(map[string]string{"job": "webapp"}), "regular expressions must be anchored")
+		assert.False(t, matchers[1].matches(map[string]string{"instance": "canary-1"}))
+	})
+}
+
+func TestPromSeries(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	series := promSeries([]*metricspb.ResourceMetrics{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+			{Key: "service.name", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "api"}}},
+		}},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Metrics: []*metricspb.Metric{
+				{
+					Name: "http.requests",
+					Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{IsMonotonic: true, DataPoints: []*metricspb.NumberDataPoint{
+						{TimeUnixNano: uint64(now.UnixNano()), Value: &metricspb.NumberDataPoint_AsInt{AsInt: 2}},
+						{TimeUnixNano: uint64(now.Add(-time.Second).UnixNano()), Value: &metricspb.NumberDataPoint_AsInt{AsInt: 1}},
+					}}},
+				},
+				{
+					Name: "latency",
+					Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{DataPoints: []*metricspb.HistogramDataPoint{{
+						TimeUnixNano:   uint64(now.UnixNano()),
+						Count:          3,
+						BucketCounts:   []uint64{1, 2},
+						ExplicitBounds: []float64{0.5},
+					}}}},
+				},
+			},
+		}},
+	}})
+
+	names := map[string][]string{}
+	for _, s := range series {
+		names[s.labels["__name__"]] = append(names[s.labels["__name__"]], s.labels["le"])
+		assert.Equal(t, "api", s.labels["service_name"])
+		assert.Equal(t, "api", s.labels["job"])
+	}
+	assert.Equal(t, map[string][]string{
+		"http_requests_total": {""},
+		"latency_count":       {""},
+		"latency_bucket":      {"+Inf", "0.5"},
+	}, names)
+
+	counter := selectSeries(series, []matcher{{name: "__name__", op: "=", value: "http_requests_total"}})
+	require.Len(t, counter, 1)
+	require.Len(t, counter[0].samples, 2)
+	assert.Equal(t, 1.0, counter[0].samples[0].v, "the samples must be ordered by time")
+
+	sample, ok := counter[0].at(now.Add(-time.Millisecond * 500))
+	require.True(t, ok)
+	assert.Equal(t, 1.0, sample.v)
+	_, ok = counter[0].at(now.Add(-time.Second * 2))
+	assert.False(t, ok, "there is no sample before the first one")
+	_, ok = counter[0].at(now.Add(lookback + time.Second))
+	assert.False(t, ok, "samples older than the lookback window must not be returned")
+
+	buckets := selectSeries(series, []matcher{{name: "__name__", op: "=", value: "latency_bucket"}, {name: "le", op: "=", value: "+Inf"}})
+	require.Len(t, buckets, 1)
+	assert.Equal(t, 3.0, buckets[0].samples[0].v, "the buckets must be cumulative")
+}
+
+func TestQueryRange(t *testing.T) {
+	t.Parallel()
+	now := time.Now().Truncate(time.Second)
+
+	b := &Backend{}
+	b.addMetrics([]*metricspb.ResourceMetrics{{
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Metrics: []*metricspb.Metric{{
+				Name: "goroutine.count",
+				Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{
+					{TimeUnixNano: uint64(now.Add(-time.Second * 25).UnixNano()), Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 4}},
+					{TimeUnixNano: uint64(now.Add(-time.Second * 5).UnixNano()), Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 6.5}},
+				}}},
+			}},
+		}},
+	}})
+	handler := b.queryHandler()
+
+	query := func(path string, values url.Values) (*httptest.ResponseRecorder, promResponse) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?"+values.Encode(), nil))
+		var resp promResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec, resp
+	}
+
+	rec, resp := query("/api/v1/query_range", url.Values{
+		"query": {"goroutine_count"},
+		"start": {now.Add(-time.Second * 30).Format(time.RFC3339)},
+		"end":   {now.Format(time.RFC3339)},
+		"step":  {"10s"},
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "matrix", resp.Data.ResultType)
+	require.Len(t, resp.Data.Result, 1)
+	values := resp.Data.Result[0].(map[string]any)["values"].([]any)
+	require.Len(t, values, 3, "the first step is before the first sample")
+	assert.Equal(t, []any{float64(now.Add(-time.Second * 20).Unix()), "4"}, values[0])
+	assert.Equal(t, []any{float64(now.Unix()), "6.5"}, values[2])
+
+	rec, resp = query("/api/v1/query", url.Values{
+		"query": {`goroutine_count{job="missing"}`},
+		"time":  {fmt.Sprint(now.Unix())},
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "vector", resp.Data.ResultType)
+	assert.Empty(t, resp.Data.Result)
+
+	rec, resp = query("/api/v1/query", url.Values{"query": {"rate(goroutine_count[1m])"}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "bad_data", resp.ErrorType)
+}
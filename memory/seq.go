@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+type seqEvent struct {
+	Timestamp  time.Time     `json:"Timestamp"`
+	Properties []seqProperty `json:"Properties"`
+	Messages   []seqMessage  `json:"MessageTemplateTokens"`
+	Exception  string        `json:"Exception,omitempty"`
+	Level      string        `json:"Level"`
+	TraceID    string        `json:"TraceId,omitempty"`
+	SpanID     string        `json:"SpanId,omitempty"`
+	Resource   []seqProperty `json:"Resource"`
+	ID         string        `json:"Id"`
+}
+
+type seqMessage struct {
+	Text string `json:"Text"`
+}
+
+type seqProperty struct {
+	Name  string `json:"Name"`
+	Value any    `json:"Value"`
+}
+
+// handleEvents answers /api/events?count=<n> with the most recent log records, translated the way Seq translates
+// OTLP logs.
+func (b *Backend) handleEvents(w http.ResponseWriter, r *http.Request) {
+	count := 30
+	if c := r.URL.Query().Get("count"); c != "" {
+		var err error
+		count, err = strconv.Atoi(c)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid count %q: %v", c, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	_, resourceLogs, _ := b.snapshot()
+	events := seqEvents(resourceLogs)
+	if len(events) > count {
+		events = events[:count]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events) //nolint:errcheck
+}
+
+// seqEvents returns the log records as events, most recently received first.
+func seqEvents(resourceLogs []*logspb.ResourceLogs) []seqEvent {
+	events := []seqEvent{}
+	for _, rl := range resourceLogs {
+		resource := seqProperties(rl.GetResource().GetAttributes())
+		for _, sl := range rl.GetScopeLogs() {
+			for _, l := range sl.GetLogRecords() {
+				timestamp := l.GetTimeUnixNano()
+				if timestamp == 0 {
+					timestamp = l.GetObservedTimeUnixNano()
+				}
+
+				event := seqEvent{
+					Timestamp:  time.Unix(0, int64(timestamp)).UTC(),
+					Properties: []seqProperty{},
+					Messages:   []seqMessage{{Text: stringValue(l.GetBody())}},
+					Level:      seqLevel(l),
+					Resource:   resource,
+					ID:         "event-" + strconv.Itoa(len(events)+1),
+				}
+				if len(l.GetTraceId()) > 0 {
+					event.TraceID = hex.EncodeToString(l.GetTraceId())
+				}
+				if len(l.GetSpanId()) > 0 {
+					event.SpanID = hex.EncodeToString(l.GetSpanId())
+				}
+
+				var attributes []*commonpb.KeyValue
+				for _, kv := range l.GetAttributes() {
+					if kv.GetKey() == "exception.stacktrace" {
+						event.Exception = stringValue(kv.GetValue())
+						continue
+					}
+					attributes = append(attributes, kv)
+				}
+				event.Properties = seqProperties(attributes)
+
+				events = append(events, event)
+			}
+		}
+	}
+
+	slices.Reverse(events)
+	return events
+}
+
+// seqLevel returns the severity text of the record, or the level that Seq derives from its severity number.
+func seqLevel(l *logspb.LogRecord) string {
+	if l.GetSeverityText() != "" {
+		return l.GetSeverityText()
+	}
+
+	switch n := l.GetSeverityNumber(); {
+	case n == logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED:
+		return "Information"
+	case n < logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG:
+		return "Verbose"
+	case n < logspb.SeverityNumber_SEVERITY_NUMBER_INFO:
+		return "Debug"
+	case n < logspb.SeverityNumber_SEVERITY_NUMBER_WARN:
+		return "Information"
+	case n < logspb.SeverityNumber_SEVERITY_NUMBER_ERROR:
+		return "Warning"
+	case n < logspb.SeverityNumber_SEVERITY_NUMBER_FATAL:
+		return "Error"
+	default:
+		return "Fatal"
+	}
+}
+
+// seqProperties nests dotted attribute names into objects the way Seq does, so that service.name becomes the
+// name field of the service property.
+func seqProperties(attributes []*commonpb.KeyValue) []seqProperty {
+	properties := []seqProperty{}
+	index := map[string]int{}
+
+	for _, kv := range attributes {
+		head, rest, nested := strings.Cut(kv.GetKey(), ".")
+		if !nested {
+			index[head] = len(properties)
+			properties = append(properties, seqProperty{Name: head, Value: backend.ValueFromOTLP(kv.GetValue())})
+			continue
+		}
+
+		i, ok := index[head]
+		if !ok {
+			i = len(properties)
+			index[head] = i
+			properties = append(properties, seqProperty{Name: head, Value: map[string]any{}})
+		}
+		object, ok := properties[i].Value.(map[string]any)
+		if !ok {
+			// a scalar already holds the name, so the attribute keeps its full name
+			index[kv.GetKey()] = len(properties)
+			properties = append(properties, seqProperty{Name: kv.GetKey(), Value: backend.ValueFromOTLP(kv.GetValue())})
+			continue
+		}
+		setNested(object, rest, backend.ValueFromOTLP(kv.GetValue()))
+	}
+
+	return properties
+}
+
+func setNested(object map[string]any, key string, v any) {
+	head, rest, nested := strings.Cut(key, ".")
+	if !nested {
+		object[key] = v
+		return
+	}
+
+	child, ok := object[head].(map[string]any)
+	if !ok {
+		if _, exists := object[head]; exists {
+			object[key] = v
+			return
+		}
+		child = map[string]any{}
+		object[head] = child
+	}
+	setNested(child, rest, v)
+}
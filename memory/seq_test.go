@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+func stringAttribute(key string, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+func TestSeqProperties(t *testing.T) {
+	t.Parallel()
+
+	properties := seqProperties([]*commonpb.KeyValue{
+		stringAttribute("service.name", "api"),
+		stringAttribute("service.version", "1.0.0"),
+		stringAttribute("level", "high"),
+		stringAttribute("level.detail", "nested under a scalar"),
+		stringAttribute("http.request.method", "GET"),
+	})
+
+	assert.Equal(t, []seqProperty{
+		{Name: "service", Value: map[string]any{"name": "api", "version": "1.0.0"}},
+		{Name: "level", Value: "high"},
+		{Name: "level.detail", Value: "nested under a scalar"},
+		{Name: "http", Value: map[string]any{"request": map[string]any{"method": "GET"}}},
+	}, properties)
+}
+
+func TestSeqLevel(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		record   *logspb.LogRecord
+		expected string
+	}{
+		{&logspb.LogRecord{SeverityText: "WARN", SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_ERROR}, "WARN"},
+		{&logspb.LogRecord{}, "Information"},
+		{&logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_TRACE2}, "Verbose"},
+		{&logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG}, "Debug"},
+		{&logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_INFO4}, "Information"},
+		{&logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_WARN}, "Warning"},
+		{&logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_ERROR3}, "Error"},
+		{&logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_FATAL}, "Fatal"},
+	}
+
+	for _, tt := range testData {
+		assert.Equal(t, tt.expected, seqLevel(tt.record), tt.record.GetSeverityNumber().String())
+	}
+}
+
+func TestSeqEvents(t *testing.T) {
+	t.Parallel()
+
+	events := seqEvents([]*logspb.ResourceLogs{{
+		ScopeLogs: []*logspb.ScopeLogs{{
+			LogRecords: []*logspb.LogRecord{
+				{ObservedTimeUnixNano: 1, Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "first"}}},
+				{
+					TimeUnixNano: 2,
+					Body:         &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "second"}},
+					Attributes:   []*commonpb.KeyValue{stringAttribute("exception.stacktrace", "panic: boom")},
+					TraceId:      []byte{0x01, 0x02},
+				},
+			},
+		}},
+	}})
+
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "second", events[0].Messages[0].Text, "the most recent event must be first")
+		assert.Equal(t, "panic: boom", events[0].Exception)
+		assert.Empty(t, events[0].Properties, "the stack trace must not be duplicated as a property")
+		assert.Equal(t, "0102", events[0].TraceID)
+		assert.Equal(t, int64(1), events[1].Timestamp.UnixNano(), "the observed time is used when the time is not set")
+	}
+}
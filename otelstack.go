@@ -10,31 +10,291 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"testing"
 
+	"github.com/adreasnow/otelstack/aspire"
+	"github.com/adreasnow/otelstack/backend"
 	"github.com/adreasnow/otelstack/collector"
+	"github.com/adreasnow/otelstack/grafana"
 	"github.com/adreasnow/otelstack/jaeger"
+	"github.com/adreasnow/otelstack/lgtm"
+	"github.com/adreasnow/otelstack/loki"
+	"github.com/adreasnow/otelstack/memory"
 	"github.com/adreasnow/otelstack/prometheus"
+	"github.com/adreasnow/otelstack/proxy"
+	"github.com/adreasnow/otelstack/registry"
 	"github.com/adreasnow/otelstack/seq"
+	"github.com/adreasnow/otelstack/tempo"
+	"github.com/adreasnow/otelstack/victoriametrics"
+	"github.com/adreasnow/otelstack/zipkin"
 
+	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/network"
 )
 
 // Stack holds structs containing to all the testcontainers.
+// Proxy is the fault-injecting proxy in front of the collector, and is only set with WithFaultProxy.
+// Memory is the in-process backend that replaces the containers, and is only set with WithInMemory.
+// Tempo replaces Jaeger as the trace backend, and is only set with WithTempo.
+// Zipkin replaces Jaeger as the trace backend, and is only set with WithZipkin.
+// Loki replaces Seq as the log backend, and is only set with WithLoki.
+// LGTM is the all-in-one container that replaces every other container, and is only set with WithLGTM;
+// Tempo and Loki are then set to its internal APIs once the stack has started.
+// VictoriaMetrics replaces Prometheus as the metrics backend, and is only set with WithVictoriaMetrics.
+// Grafana is provisioned with the stack's backends as datasources, and is only set with WithGrafana.
+// Aspire is the dashboard that every signal is also exported to, and is only set with WithAspire.
 type Stack struct {
-	Collector  collector.Collector
-	Jaeger     jaeger.Jaeger
-	Seq        seq.Seq
-	Prometheus prometheus.Prometheus
-	metrics    bool
-	logs       bool
-	traces     bool
+	Collector       collector.Collector
+	Jaeger          jaeger.Jaeger
+	Seq             seq.Seq
+	Prometheus      prometheus.Prometheus
+	Proxy           *proxy.Proxy
+	Memory          *memory.Backend
+	Tempo           *tempo.Tempo
+	Zipkin          *zipkin.Zipkin
+	Loki            *loki.Loki
+	LGTM            *lgtm.LGTM
+	Aspire          *aspire.Aspire
+	VictoriaMetrics *victoriametrics.VictoriaMetrics
+	Grafana         *grafana.Grafana
+	traceBackend    backend.TraceBackend
+	logBackend      backend.LogBackend
+	metricBackend   backend.MetricBackend
+	metrics         bool
+	logs            bool
+	traces          bool
+	external        *External
+	network         *testcontainers.DockerNetwork
+	registry        *registry.Config
+	aliasPrefix     string
+	lgtmBackends    bool
+}
+
+// Option configures optional behaviour of a Stack.
+type Option func(*Stack)
+
+// WithRegistry applies the registry configuration to the images of every container in the stack.
+func WithRegistry(r *registry.Config) Option {
+	return func(s *Stack) {
+		s.registry = r
+	}
+}
+
+// WithNetwork starts the stack on an existing network instead of creating a new one. The network
+// is not removed when the stack is shut down.
+func WithNetwork(n *testcontainers.DockerNetwork) Option {
+	return func(s *Stack) {
+		s.network = n
+	}
+}
+
+// WithAliasPrefix prefixes the network aliases of every container in the stack (e.g. "a" gives
+// "a-collector"), so that several stacks can share one network.
+func WithAliasPrefix(prefix string) Option {
+	return func(s *Stack) {
+		s.aliasPrefix = prefix
+	}
+}
+
+// WithCapture makes the collector write every signal it receives to a file, which can be read
+// with Collector.Captured to assert on exactly what was sent.
+func WithCapture() Option {
+	return func(s *Stack) {
+		s.Collector.Capture = true
+	}
+}
+
+// WithTLS serves the collector's OTLP receivers over TLS with a generated CA and server certificate,
+// optionally requiring clients to present the generated client certificate (see collector.TLS).
+func WithTLS(requireClientCert bool) Option {
+	return func(s *Stack) {
+		s.Collector.TLS = &collector.TLS{RequireClientCert: requireClientCert}
+	}
+}
+
+// WithBearerToken requires exporters to authenticate to the collector with the given bearer token.
+func WithBearerToken(token string) Option {
+	return func(s *Stack) {
+		s.Collector.Auth = collector.BearerToken{Token: token}
+	}
+}
+
+// WithBasicAuth requires exporters to authenticate to the collector with the given username and password.
+func WithBasicAuth(username string, password string) Option {
+	return func(s *Stack) {
+		s.Collector.Auth = collector.BasicAuth{Username: username, Password: password}
+	}
+}
+
+// WithMetadataHeaders promotes the given request headers into attributes of the received telemetry and
+// enables capture, so that the headers of each export request can be read with Collector.ExportRequests.
+func WithMetadataHeaders(headers ...string) Option {
+	return func(s *Stack) {
+		s.Collector.MetadataHeaders = headers
+		s.Collector.Capture = true
+	}
+}
+
+// WithSpanMetrics derives request rate, error and duration metrics from the spans with the collector's spanmetrics
+// connector, labelled by the given extra dimensions, so that they can be queried with Prometheus.GetSpanMetrics.
+func WithSpanMetrics(dimensions ...string) Option {
+	return func(s *Stack) {
+		s.Collector.SpanMetrics = &collector.SpanMetrics{Dimensions: dimensions}
+	}
+}
+
+// WithServiceGraph derives the edges between services from the spans with the collector's servicegraph connector,
+// so that they can be queried with Prometheus.GetServiceGraph. Jaeger.GetDependencies works without it.
+func WithServiceGraph() Option {
+	return func(s *Stack) {
+		s.Collector.ServiceGraph = &collector.ServiceGraph{}
+	}
+}
+
+// WithFaultProxy starts an in-process proxy in front of the collector's OTLP ports, which can inject errors, latency
+// and dropped connections at runtime (see proxy.Proxy). SetTestEnvGRPC and SetTestEnvHTTP point the exporters at the proxy.
+func WithFaultProxy() Option {
+	return func(s *Stack) {
+		s.Proxy = &proxy.Proxy{}
+	}
+}
+
+// WithTempo starts Grafana Tempo instead of Jaeger when traces are enabled, and exports the collector's traces to it,
+// so that they can be queried with TraceQL (see tempo.Tempo.Search).
+func WithTempo() Option {
+	return func(s *Stack) {
+		s.Tempo = &tempo.Tempo{}
+	}
+}
+
+// WithZipkin starts Zipkin instead of Jaeger when traces are enabled, and exports the collector's traces to it
+// as Zipkin v2 spans (see zipkin.Zipkin.GetTraces). It can't be combined with WithTempo.
+func WithZipkin() Option {
+	return func(s *Stack) {
+		s.Zipkin = &zipkin.Zipkin{}
+	}
+}
+
+// WithZipkinReceiver enables the collector's zipkin receiver on port 9411, so that services emitting Zipkin spans
+// can be tested alongside OTLP ones. The spans are sent to Collector.Endpoint(9411) + "/api/v2/spans".
+func WithZipkinReceiver() Option {
+	return func(s *Stack) {
+		s.Collector.ZipkinReceiver = true
+	}
+}
+
+// WithPrometheusOTLP makes the collector push metrics to Prometheus' native OTLP receiver instead of Prometheus scraping
+// the collector, which removes the scrape delay, keeps exemplars, and names the metrics with the given translation
+// strategy like a production Prometheus receiving OTLP (see prometheus.OTLP). The span metrics and service graph
+// helpers expect the underscore escaped names.
+func WithPrometheusOTLP(strategy prometheus.TranslationStrategy) Option {
+	return func(s *Stack) {
+		s.Prometheus.OTLP = &prometheus.OTLP{TranslationStrategy: strategy}
+	}
+}
+
+// WithVictoriaMetrics starts a single-node VictoriaMetrics instead of Prometheus when metrics are enabled, which
+// either scrapes the collector or, with otlp, receives the metrics over OTLP, so that they can be queried with
+// MetricsQL (see victoriametrics.VictoriaMetrics.QueryRange). It can't be combined with WithPrometheusOTLP.
+func WithVictoriaMetrics(otlp bool) Option {
+	return func(s *Stack) {
+		s.VictoriaMetrics = &victoriametrics.VictoriaMetrics{OTLP: otlp}
+	}
+}
+
+// WithLoki starts Grafana Loki instead of Seq when logs are enabled, and exports the collector's logs to Loki's
+// OTLP endpoint, so that they can be queried with LogQL (see loki.Loki.QueryRange).
+func WithLoki() Option {
+	return func(s *Stack) {
+		s.Loki = &loki.Loki{}
+	}
+}
+
+// WithAspire starts the .NET Aspire dashboard and exports every signal to it alongside the other backends, so that
+// the telemetry can be browsed in one UI while debugging. Aspire.LoginURL logs into the UI with Aspire.BrowserToken.
+func WithAspire() Option {
+	return func(s *Stack) {
+		s.Aspire = &aspire.Aspire{}
+	}
+}
+
+// WithGrafana starts Grafana last, provisioned with the stack's trace and metrics backends, and Loki when enabled,
+// as datasources (Seq has no built-in datasource). Anonymous users are admins, and LogExploreURLs logs a link to
+// Explore for each datasource.
+func WithGrafana() Option {
+	return func(s *Stack) {
+		s.Grafana = &grafana.Grafana{}
+	}
+}
+
+// WithTraceBackend replaces Jaeger as the stack's TraceBackend, e.g. with a fake, or with a backend that the
+// telemetry is exported to separately. Jaeger is still started when traces are enabled.
+func WithTraceBackend(b backend.TraceBackend) Option {
+	return func(s *Stack) {
+		s.traceBackend = b
+	}
+}
+
+// WithLogBackend replaces Seq as the stack's LogBackend. Seq is still started when logs are enabled.
+func WithLogBackend(b backend.LogBackend) Option {
+	return func(s *Stack) {
+		s.logBackend = b
+	}
+}
+
+// WithMetricBackend replaces Prometheus as the stack's MetricBackend. Prometheus is still started when metrics are enabled.
+func WithMetricBackend(b backend.MetricBackend) Option {
+	return func(s *Stack) {
+		s.metricBackend = b
+	}
+}
+
+// TraceBackend returns the backend that traces are queried from, which is Jaeger (or Tempo with WithTempo,
+// or Zipkin with WithZipkin) unless replaced with WithTraceBackend.
+func (s *Stack) TraceBackend() backend.TraceBackend {
+	if s.traceBackend != nil {
+		return s.traceBackend
+	}
+	if s.Tempo != nil {
+		return s.Tempo
+	}
+	if s.Zipkin != nil {
+		return s.Zipkin
+	}
+	return &s.Jaeger
+}
+
+// LogBackend returns the backend that logs are queried from, which is Seq (or Loki with WithLoki)
+// unless replaced with WithLogBackend.
+func (s *Stack) LogBackend() backend.LogBackend {
+	if s.logBackend != nil {
+		return s.logBackend
+	}
+	if s.Loki != nil {
+		return s.Loki
+	}
+	return &s.Seq
+}
+
+// MetricBackend returns the backend that metrics are queried from, which is Prometheus (or VictoriaMetrics with
+// WithVictoriaMetrics) unless replaced with WithMetricBackend.
+func (s *Stack) MetricBackend() backend.MetricBackend {
+	if s.metricBackend != nil {
+		return s.metricBackend
+	}
+	if s.VictoriaMetrics != nil {
+		return s.VictoriaMetrics
+	}
+	return &s.Prometheus
 }
 
 // New creates a new Stack and populates it with child container structs.
 // Setting the services toggles will disables or enable the respective receiver containers.
-func New(metrics bool, logs bool, traces bool) *Stack {
-	return &Stack{
+// If any of the OTELSTACK_*_ENDPOINT environment variables are set, the stack will attach to
+// the external services they point to instead of starting containers (see ExternalFromEnv).
+func New(metrics bool, logs bool, traces bool, opts ...Option) *Stack {
+	s := &Stack{
 		Collector:  collector.Collector{},
 		Jaeger:     jaeger.Jaeger{},
 		Seq:        seq.Seq{},
@@ -43,29 +303,163 @@ func New(metrics bool, logs bool, traces bool) *Stack {
 		logs:       logs,
 		traces:     traces,
 	}
+
+	if external, ok := ExternalFromEnv(); ok {
+		s.external = &external
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	// applied once every option has run, as the options that add a backend can come in any order
+	s.applyRegistry()
+	s.applyAliasPrefix()
+
+	return s
+}
+
+// applyRegistry applies the registry set with WithRegistry to every container of the stack.
+func (s *Stack) applyRegistry() {
+	if s.registry == nil {
+		return
+	}
+	s.Collector.Registry = s.registry
+	s.Jaeger.Registry = s.registry
+	s.Seq.Registry = s.registry
+	s.Prometheus.Registry = s.registry
+	if s.Tempo != nil {
+		s.Tempo.Registry = s.registry
+	}
+	if s.Zipkin != nil {
+		s.Zipkin.Registry = s.registry
+	}
+	if s.Loki != nil {
+		s.Loki.Registry = s.registry
+	}
+	if s.LGTM != nil {
+		s.LGTM.Registry = s.registry
+	}
+	if s.Aspire != nil {
+		s.Aspire.Registry = s.registry
+	}
+	if s.VictoriaMetrics != nil {
+		s.VictoriaMetrics.Registry = s.registry
+	}
+	if s.Grafana != nil {
+		s.Grafana.Registry = s.registry
+	}
+}
+
+// applyAliasPrefix prefixes the network alias of every container of the stack with the prefix set with WithAliasPrefix.
+func (s *Stack) applyAliasPrefix() {
+	if s.aliasPrefix == "" {
+		return
+	}
+	prefix := s.aliasPrefix + "-"
+	s.Collector.Alias = prefix + collector.DefaultAlias
+	s.Jaeger.Alias = prefix + jaeger.DefaultAlias
+	s.Seq.Alias = prefix + seq.DefaultAlias
+	s.Prometheus.Alias = prefix + prometheus.DefaultAlias
+	if s.Tempo != nil {
+		s.Tempo.Alias = prefix + tempo.DefaultAlias
+	}
+	if s.Zipkin != nil {
+		s.Zipkin.Alias = prefix + zipkin.DefaultAlias
+	}
+	if s.Loki != nil {
+		s.Loki.Alias = prefix + loki.DefaultAlias
+	}
+	if s.LGTM != nil {
+		s.LGTM.Alias = prefix + lgtm.DefaultAlias
+	}
+	if s.Aspire != nil {
+		s.Aspire.Alias = prefix + aspire.DefaultAlias
+	}
+	if s.VictoriaMetrics != nil {
+		s.VictoriaMetrics.Alias = prefix + victoriametrics.DefaultAlias
+	}
+	if s.Grafana != nil {
+		s.Grafana.Alias = prefix + grafana.DefaultAlias
+	}
 }
 
 // SetTestEnvGRPC sets the environment variableOTEL_EXPORTER_OTLP_ENDPOINT
-// to the gRPC endpoint.
+// to the gRPC endpoint, along with the certificate and header variables when the collector uses TLS or Auth.
 func (s *Stack) SetTestEnvGRPC(t *testing.T) {
-	endpoint := fmt.Sprintf("http://localhost:%d", s.Collector.Ports[4317].Int())
+	endpoint := s.Collector.Endpoint(4317)
+	if s.Proxy != nil {
+		endpoint = s.Proxy.GRPCEndpoint()
+	}
 	t.Logf(" setting endpoint to %s", endpoint)
 	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", endpoint)
+	s.setTestEnvTLS(t)
+	s.setTestEnvAuth(t)
 }
 
 // SetTestEnvHTTP sets the environment variableOTEL_EXPORTER_OTLP_ENDPOINT
-// to the HTTP endpoint
+// to the HTTP endpoint, along with the certificate and header variables when the collector uses TLS or Auth.
 func (s *Stack) SetTestEnvHTTP(t *testing.T) {
-	endpoint := fmt.Sprintf("http://localhost:%d", s.Collector.Ports[4318].Int())
+	endpoint := s.Collector.Endpoint(4318)
+	if s.Proxy != nil {
+		endpoint = s.Proxy.HTTPEndpoint()
+	}
 	t.Logf(" setting endpoint to %s", endpoint)
 	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", endpoint)
+	s.setTestEnvTLS(t)
+	s.setTestEnvAuth(t)
+}
+
+// setTestEnvAuth sets the Authorization header that the exporters send when the collector requires authentication.
+func (s *Stack) setTestEnvAuth(t *testing.T) {
+	if s.Collector.Auth == nil {
+		return
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", s.Collector.EnvHeaders())
+}
+
+// setTestEnvTLS points the exporters at the generated CA and client certificate when the collector uses TLS.
+func (s *Stack) setTestEnvTLS(t *testing.T) {
+	if s.Collector.TLS == nil {
+		return
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_CERTIFICATE", s.Collector.TLS.CAFile)
+	if s.Collector.TLS.RequireClientCert {
+		t.Setenv("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE", s.Collector.TLS.ClientCertFile)
+		t.Setenv("OTEL_EXPORTER_OTLP_CLIENT_KEY", s.Collector.TLS.ClientKeyFile)
+	}
 }
 
-// Start creates a testcontainer network and starts up all the child containers.
+// Start creates a testcontainer network (unless one was given with WithNetwork) and starts up all the child containers.
+// If the stack is in external mode, no containers are started and Start only verifies that the
+// external services are reachable. If the stack is in-memory, no containers are started either.
 func (s *Stack) Start(ctx context.Context) (func(context.Context) error, error) {
+	if s.Memory != nil {
+		return s.startMemory(ctx)
+	}
+	if s.LGTM != nil {
+		return s.startLGTM(ctx)
+	}
+	if s.external != nil {
+		return s.startExternal(ctx)
+	}
+
 	shutdownFuncs := []func(context.Context) error{}
 	emptyFunc := func(context.Context) error { return nil }
 
+	if s.Tempo != nil && s.Zipkin != nil {
+		return emptyFunc, errors.New("otelstack: only one of Tempo and Zipkin can replace Jaeger")
+	}
+	if s.VictoriaMetrics != nil && s.Prometheus.OTLP != nil {
+		return emptyFunc, errors.New("otelstack: Prometheus OTLP ingestion can't be combined with VictoriaMetrics, use WithVictoriaMetrics(true) instead")
+	}
+	if err := s.checkExporters(); err != nil {
+		return emptyFunc, err
+	}
+	if s.Proxy != nil && s.Collector.TLS != nil {
+		return emptyFunc, errors.New("otelstack: the fault proxy can't forward to a collector using TLS")
+	}
+
 	shutdown := func(ctx context.Context) error {
 		// Reverse the slice so that the network is shut down last
 		slices.Reverse(shutdownFuncs)
@@ -80,14 +474,51 @@ func (s *Stack) Start(ctx context.Context) (func(context.Context) error, error)
 		return nil
 	}
 
-	network, err := network.New(ctx)
-	if err != nil {
-		return shutdown, fmt.Errorf("otelstack: could not create new network: %w", err)
+	stackNetwork := s.network
+	if stackNetwork == nil {
+		var err error
+		stackNetwork, err = network.New(ctx)
+		if err != nil {
+			return shutdown, fmt.Errorf("otelstack: could not create new network: %w", err)
+		}
+		shutdownFuncs = append(shutdownFuncs, stackNetwork.Remove)
 	}
-	shutdownFuncs = append(shutdownFuncs, network.Remove)
 
-	if s.traces {
-		s.Jaeger.Network = network
+	if s.traces && s.Tempo != nil {
+		s.Tempo.Network = stackNetwork
+		tempoShutdown, err := s.Tempo.Start(ctx)
+		if err != nil {
+			err = fmt.Errorf("otelstack: could not start tempo: %w", err)
+			if shutdownErr := shutdown(ctx); shutdownErr != nil {
+				err = errors.Join(
+					err, fmt.Errorf("otelstack: error occurred while shutting down services after failed tempo start: %w", shutdownErr),
+				)
+			}
+			return emptyFunc, err
+		}
+		shutdownFuncs = append(shutdownFuncs, tempoShutdown)
+
+		s.setExporter(collector.Traces, collector.OTLPExporter(tempoExporter, s.Tempo.Alias+":4317"))
+	} else if s.traces && s.Zipkin != nil {
+		s.Zipkin.Network = stackNetwork
+		zipkinShutdown, err := s.Zipkin.Start(ctx)
+		if err != nil {
+			err = fmt.Errorf("otelstack: could not start zipkin: %w", err)
+			if shutdownErr := shutdown(ctx); shutdownErr != nil {
+				err = errors.Join(
+					err, fmt.Errorf("otelstack: error occurred while shutting down services after failed zipkin start: %w", shutdownErr),
+				)
+			}
+			return emptyFunc, err
+		}
+		shutdownFuncs = append(shutdownFuncs, zipkinShutdown)
+
+		s.setExporter(collector.Traces, collector.Exporter{
+			Name:   zipkinExporter,
+			Config: map[string]any{"endpoint": s.Zipkin.SpansEndpoint(), "format": "json"},
+		})
+	} else if s.traces {
+		s.Jaeger.Network = stackNetwork
 		jaegerShutdown, err := s.Jaeger.Start(ctx)
 		if err != nil {
 			err = fmt.Errorf("otelstack: could not start jaeger: %w", err)
@@ -101,8 +532,23 @@ func (s *Stack) Start(ctx context.Context) (func(context.Context) error, error)
 		shutdownFuncs = append(shutdownFuncs, jaegerShutdown)
 	}
 
-	if s.logs {
-		s.Seq.Network = network
+	if s.logs && s.Loki != nil {
+		s.Loki.Network = stackNetwork
+		lokiShutdown, err := s.Loki.Start(ctx)
+		if err != nil {
+			err = fmt.Errorf("otelstack: could not start loki: %w", err)
+			if shutdownErr := shutdown(ctx); shutdownErr != nil {
+				err = errors.Join(
+					err, fmt.Errorf("otelstack: error occurred while shutting down services after failed loki start: %w", shutdownErr),
+				)
+			}
+			return emptyFunc, err
+		}
+		shutdownFuncs = append(shutdownFuncs, lokiShutdown)
+
+		s.setExporter(collector.Logs, collector.OTLPHTTPExporter(lokiExporter, s.Loki.OTLPEndpoint()))
+	} else if s.logs {
+		s.Seq.Network = stackNetwork
 		seqShutdown, err := s.Seq.Start(ctx)
 		if err != nil {
 			err = fmt.Errorf("otelstack: could not start seq: %w", err)
@@ -116,8 +562,35 @@ func (s *Stack) Start(ctx context.Context) (func(context.Context) error, error)
 		shutdownFuncs = append(shutdownFuncs, seqShutdown)
 	}
 
-	s.Collector.Network = network
-	collectorShutdown, err := s.Collector.Start(ctx, s.Jaeger.Name, s.Seq.Name)
+	if s.Aspire != nil {
+		s.Aspire.Network = stackNetwork
+		aspireShutdown, err := s.Aspire.Start(ctx)
+		if err != nil {
+			err = fmt.Errorf("otelstack: could not start aspire: %w", err)
+			if shutdownErr := shutdown(ctx); shutdownErr != nil {
+				err = errors.Join(
+					err, fmt.Errorf("otelstack: error occurred while shutting down services after failed aspire start: %w", shutdownErr),
+				)
+			}
+			return emptyFunc, err
+		}
+		shutdownFuncs = append(shutdownFuncs, aspireShutdown)
+
+		s.setExtraExporter(collector.OTLPExporter(aspireExporter, s.Aspire.OTLPEndpoint()))
+	}
+
+	s.pushMetrics()
+
+	var jaegerAlias, seqAlias string
+	if s.traces && s.Tempo == nil && s.Zipkin == nil {
+		jaegerAlias = s.Jaeger.Alias
+	}
+	if s.logs && s.Loki == nil {
+		seqAlias = s.Seq.Alias
+	}
+
+	s.Collector.Network = stackNetwork
+	collectorShutdown, err := s.Collector.Start(ctx, jaegerAlias, seqAlias)
 	if err != nil {
 		err = fmt.Errorf("otelstack: could not start collector: %w", err)
 		if shutdownErr := shutdown(ctx); shutdownErr != nil {
@@ -129,9 +602,38 @@ func (s *Stack) Start(ctx context.Context) (func(context.Context) error, error)
 	}
 	shutdownFuncs = append(shutdownFuncs, collectorShutdown)
 
-	if s.metrics {
-		s.Prometheus.Network = network
-		prometheusShutdown, err := s.Prometheus.Start(ctx, s.Collector.Name)
+	if s.Proxy != nil {
+		s.Proxy.GRPCTarget = strings.TrimPrefix(s.Collector.Endpoint(4317), "http://")
+		s.Proxy.HTTPTarget = s.Collector.Endpoint(4318)
+		proxyShutdown, err := s.Proxy.Start(ctx)
+		if err != nil {
+			err = fmt.Errorf("otelstack: could not start the fault proxy: %w", err)
+			if shutdownErr := shutdown(ctx); shutdownErr != nil {
+				err = errors.Join(
+					err, fmt.Errorf("otelstack: error occurred while shutting down services after failed proxy start: %w", shutdownErr),
+				)
+			}
+			return emptyFunc, err
+		}
+		shutdownFuncs = append(shutdownFuncs, proxyShutdown)
+	}
+
+	if s.metrics && s.VictoriaMetrics != nil {
+		s.VictoriaMetrics.Network = stackNetwork
+		victoriaMetricsShutdown, err := s.VictoriaMetrics.Start(ctx, s.Collector.Alias)
+		if err != nil {
+			err = fmt.Errorf("otelstack: could not start victoriametrics: %w", err)
+			if shutdownErr := shutdown(ctx); shutdownErr != nil {
+				err = errors.Join(
+					err, fmt.Errorf("otelstack: error occurred while shutting down services after failed victoriametrics start: %w", shutdownErr),
+				)
+			}
+			return emptyFunc, err
+		}
+		shutdownFuncs = append(shutdownFuncs, victoriaMetricsShutdown)
+	} else if s.metrics {
+		s.Prometheus.Network = stackNetwork
+		prometheusShutdown, err := s.Prometheus.Start(ctx, s.Collector.Alias)
 		if err != nil {
 			err = fmt.Errorf("otelstack: could not start prometheus: %w", err)
 			if shutdownErr := shutdown(ctx); shutdownErr != nil {
@@ -144,5 +646,138 @@ func (s *Stack) Start(ctx context.Context) (func(context.Context) error, error)
 		shutdownFuncs = append(shutdownFuncs, prometheusShutdown)
 	}
 
+	if s.Grafana != nil {
+		s.Grafana.Network = stackNetwork
+		s.Grafana.StackDatasources = s.grafanaDatasources()
+		grafanaShutdown, err := s.Grafana.Start(ctx)
+		if err != nil {
+			err = fmt.Errorf("otelstack: could not start grafana: %w", err)
+			if shutdownErr := shutdown(ctx); shutdownErr != nil {
+				err = errors.Join(
+					err, fmt.Errorf("otelstack: error occurred while shutting down services after failed grafana start: %w", shutdownErr),
+				)
+			}
+			return emptyFunc, err
+		}
+		shutdownFuncs = append(shutdownFuncs, grafanaShutdown)
+	}
+
 	return shutdown, nil
 }
+
+// grafanaDatasources returns the datasources of the started backends, addressed by their network aliases.
+func (s *Stack) grafanaDatasources() []grafana.Datasource {
+	var datasources []grafana.Datasource
+	switch {
+	case !s.traces:
+	case s.Tempo != nil:
+		datasources = append(datasources, grafana.Datasource{Name: "Tempo", Type: "tempo", UID: "tempo", URL: "http://" + s.Tempo.Alias + ":3200"})
+	case s.Zipkin != nil:
+		datasources = append(datasources, grafana.Datasource{Name: "Zipkin", Type: "zipkin", UID: "zipkin", URL: "http://" + s.Zipkin.Alias + ":9411"})
+	default:
+		datasources = append(datasources, grafana.Datasource{Name: "Jaeger", Type: "jaeger", UID: "jaeger", URL: "http://" + s.Jaeger.Alias + ":16686"})
+	}
+	if s.logs && s.Loki != nil {
+		datasources = append(datasources, grafana.Datasource{Name: "Loki", Type: "loki", UID: "loki", URL: "http://" + s.Loki.Alias + ":3100"})
+	}
+	switch {
+	case !s.metrics:
+	case s.VictoriaMetrics != nil:
+		datasources = append(datasources, grafana.Datasource{
+			Name: "VictoriaMetrics", Type: "prometheus", UID: "victoriametrics", URL: "http://" + s.VictoriaMetrics.Alias + ":8428",
+		})
+	default:
+		datasources = append(datasources, grafana.Datasource{Name: "Prometheus", Type: "prometheus", UID: "prometheus", URL: "http://" + s.Prometheus.Alias + ":9090"})
+	}
+	return datasources
+}
+
+// LogExploreURLs logs a link to Grafana's Explore view for each of its datasources, which go test shows when
+// the test fails or with -v. It does nothing unless the stack was started with WithGrafana.
+func (s *Stack) LogExploreURLs(t *testing.T) {
+	if s.Grafana == nil {
+		return
+	}
+	for _, d := range s.Grafana.ProvisionedDatasources() {
+		t.Logf(" explore %s in grafana: %s", d.Name, s.Grafana.ExploreURL(d))
+	}
+}
+
+// pushMetrics replaces the collector's metrics exporter with the OTLP receiver of VictoriaMetrics or Prometheus
+// when metrics are enabled and the metrics backend receives OTLP.
+func (s *Stack) pushMetrics() {
+	if !s.metrics {
+		return
+	}
+
+	switch {
+	case s.VictoriaMetrics != nil && s.VictoriaMetrics.OTLP:
+		s.setExporter(collector.Metrics, collector.OTLPHTTPExporter(victoriaMetricsExporter, s.VictoriaMetrics.OTLPEndpoint()))
+	case s.VictoriaMetrics == nil && s.Prometheus.OTLP != nil:
+		s.setExporter(collector.Metrics, collector.OTLPHTTPExporter(prometheusExporter, s.Prometheus.OTLPEndpoint()))
+	}
+}
+
+// The names of the exporters that the stack adds to the collector for its alternative backends and dashboards.
+const (
+	tempoExporter           = "otlp/tempo"
+	zipkinExporter          = "zipkin"
+	lokiExporter            = "otlphttp/loki"
+	victoriaMetricsExporter = "otlphttp/victoriametrics"
+	prometheusExporter      = "otlphttp/prometheus"
+	aspireExporter          = "otlp/aspire"
+)
+
+// backendExporters returns, for each signal that the stack exports to an alternative backend, the name of the
+// exporter that replaces the signal's default exporter.
+func (s *Stack) backendExporters() map[collector.Signal]string {
+	names := map[collector.Signal]string{}
+	switch {
+	case !s.traces:
+	case s.Tempo != nil:
+		names[collector.Traces] = tempoExporter
+	case s.Zipkin != nil:
+		names[collector.Traces] = zipkinExporter
+	}
+	if s.logs && s.Loki != nil {
+		names[collector.Logs] = lokiExporter
+	}
+	switch {
+	case !s.metrics:
+	case s.VictoriaMetrics != nil && s.VictoriaMetrics.OTLP:
+		names[collector.Metrics] = victoriaMetricsExporter
+	case s.VictoriaMetrics == nil && s.Prometheus.OTLP != nil:
+		names[collector.Metrics] = prometheusExporter
+	}
+	return names
+}
+
+// checkExporters returns an error when Collector.Exporters sets an exporter for a signal that the stack exports to
+// an alternative backend, which would otherwise be silently replaced.
+func (s *Stack) checkExporters() error {
+	for signal, name := range s.backendExporters() {
+		if e, ok := s.Collector.Exporters[signal]; ok && e.Name != name {
+			return fmt.Errorf("otelstack: the collector's %s exporter %s conflicts with the stack's %s exporter", signal, e.Name, name)
+		}
+	}
+	return nil
+}
+
+// setExtraExporter adds an exporter to every pipeline of the collector, replacing the one of the same name that a
+// previous start added.
+func (s *Stack) setExtraExporter(exporter collector.Exporter) {
+	i := slices.IndexFunc(s.Collector.ExtraExporters, func(e collector.Exporter) bool { return e.Name == exporter.Name })
+	if i >= 0 {
+		s.Collector.ExtraExporters[i] = exporter
+		return
+	}
+	s.Collector.ExtraExporters = append(s.Collector.ExtraExporters, exporter)
+}
+
+// setExporter sets the collector's exporter for the signal, which checkExporters has made sure the user did not set.
+func (s *Stack) setExporter(signal collector.Signal, exporter collector.Exporter) {
+	if s.Collector.Exporters == nil {
+		s.Collector.Exporters = map[collector.Signal]collector.Exporter{}
+	}
+	s.Collector.Exporters[signal] = exporter
+}
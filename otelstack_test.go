@@ -2,17 +2,28 @@ package otelstack
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"runtime"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/adreasnow/otelstack/collector"
+	"github.com/adreasnow/otelstack/grafana"
+	"github.com/adreasnow/otelstack/prometheus"
+	"github.com/adreasnow/otelstack/proxy"
+	"github.com/adreasnow/otelstack/registry"
 	"github.com/docker/go-connections/nat"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/network"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
@@ -403,3 +414,644 @@ func TestNew(t *testing.T) {
 		assert.Equal(t, "test.segment", traces[0].Spans[0].OperationName)
 	})
 }
+
+func TestSharedNetwork(t *testing.T) {
+	n, err := network.New(t.Context())
+	require.NoError(t, err, "must be able to create a network")
+	t.Cleanup(func() {
+		if err := n.Remove(context.Background()); err != nil {
+			t.Logf("error removing network: %v", err)
+		}
+	})
+
+	stacks := []*Stack{
+		New(false, true, false, WithNetwork(n), WithAliasPrefix("a")),
+		New(false, true, false, WithNetwork(n), WithAliasPrefix("b")),
+	}
+
+	for _, s := range stacks {
+		shutdownStack, err := s.Start(t.Context())
+		require.NoError(t, err, "the stack must start up")
+		t.Cleanup(func() {
+			if err := shutdownStack(context.Background()); err != nil {
+				t.Logf("error shutting down stack: %v", err)
+			}
+		})
+	}
+
+	assert.Equal(t, "a-collector", stacks[0].Collector.Alias)
+	assert.Equal(t, "b-seq", stacks[1].Seq.Alias)
+
+	shutdownOTEL := setupOTELgRPC(t, false, true, false, stacks[1].Collector.Ports[4317])
+	t.Cleanup(shutdownOTEL)
+
+	{ // send data
+		record := log.Record{}
+		record.SetTimestamp(time.Now())
+		record.SetBody(log.StringValue("test message"))
+		otelLogGlobal.GetLoggerProvider().
+			Logger(serviceName).
+			Emit(t.Context(), record)
+	}
+
+	events, _, err := stacks[1].Seq.GetEvents(1, 30)
+	require.NoError(t, err, "the second stack must receive the log")
+	require.Len(t, events, 1)
+
+	events, _, err = stacks[0].Seq.GetEvents(0, 1)
+	require.NoError(t, err)
+	assert.Empty(t, events, "the first stack must not receive the log")
+}
+
+func TestCapture(t *testing.T) {
+	s := New(false, false, true, WithCapture())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	shutdownOTEL := setupOTELgRPC(t, false, false, true, s.Collector.Ports[4317])
+
+	{ // send data
+		_, span := otel.Tracer(serviceName).Start(t.Context(), "captured-span")
+		span.SetAttributes(attribute.Int("answer", 42))
+		span.End()
+	}
+	shutdownOTEL()
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		telemetry, err := s.Collector.Captured(t.Context())
+		require.NoError(c, err, "must be able to read the captured telemetry")
+		spans := telemetry.Spans()
+		require.Len(c, spans, 1)
+		assert.Equal(c, "captured-span", spans[0].Name)
+		answer, _ := spans[0].Attributes.Get("answer")
+		assert.Equal(c, int64(42), answer)
+
+		name, _ := telemetry.ResourceSpans[0].Resource.Attributes.Get("service.name")
+		assert.Equal(c, serviceName, name)
+	}, time.Second*10, time.Millisecond*200)
+
+	stats, err := s.Collector.Stats(t.Context())
+	require.NoError(t, err, "must be able to read the collector's internal metrics")
+	assert.Equal(t, int64(1), stats.Receivers["otlp"].AcceptedSpans)
+	assert.Equal(t, int64(1), stats.Exporters["file/capture"].SentSpans)
+}
+
+func TestMetadataHeaders(t *testing.T) {
+	s := New(false, false, true, WithMetadataHeaders("X-Tenant-ID"))
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	exporter, err := otlptracegrpc.New(t.Context(),
+		otlptracegrpc.WithEndpoint("localhost:"+s.Collector.Ports[4317].Port()),
+		otlptracegrpc.WithInsecure(),
+		otlptracegrpc.WithHeaders(map[string]string{"X-Tenant-ID": "tenant-a"}),
+	)
+	require.NoError(t, err, "must be able to set up exporter")
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	_, span := provider.Tracer(serviceName).Start(t.Context(), "tenant-span")
+	span.End()
+	require.NoError(t, provider.Shutdown(t.Context()))
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		requests, err := s.Collector.ExportRequests(t.Context())
+		require.NoError(c, err, "must be able to read the export requests")
+		require.Len(c, requests, 1)
+		assert.Equal(c, collector.Traces, requests[0].Signal)
+		assert.Equal(c, map[string]string{"x-tenant-id": "tenant-a"}, requests[0].Headers)
+	}, time.Second*10, time.Millisecond*200)
+}
+
+func TestSpanMetrics(t *testing.T) {
+	s := New(true, false, true, WithSpanMetrics())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	shutdownOTEL := setupOTELgRPC(t, false, false, true, s.Collector.Ports[4317])
+
+	{ // send data
+		for range 3 {
+			_, span := otel.Tracer(serviceName).Start(t.Context(), "red-span")
+			span.End()
+		}
+		_, span := otel.Tracer(serviceName).Start(t.Context(), "red-span")
+		span.SetStatus(codes.Error, "failed")
+		span.End()
+	}
+	shutdownOTEL()
+
+	metrics, _, err := s.Prometheus.GetSpanMetrics(4, 30, serviceName, "red-span", "")
+	require.NoError(t, err, "must be able to get the span metrics")
+	assert.InDelta(t, 4.0, metrics.Calls, 0)
+	assert.InDelta(t, 1.0, metrics.Errors, 0)
+	assert.InDelta(t, 4.0, metrics.DurationCount, 0)
+}
+
+func TestServiceGraph(t *testing.T) {
+	s := New(true, false, true, WithServiceGraph())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	newProvider := func(service string) *sdktrace.TracerProvider {
+		exporter, err := otlptracegrpc.New(t.Context(),
+			otlptracegrpc.WithEndpoint("localhost:"+s.Collector.Ports[4317].Port()),
+			otlptracegrpc.WithInsecure(),
+		)
+		require.NoError(t, err, "must be able to set up exporter")
+		resources, err := resource.New(t.Context(), resource.WithAttributes(attribute.String("service.name", service)))
+		require.NoError(t, err, "resources must be created")
+		return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter), sdktrace.WithResource(resources))
+	}
+	frontend := newProvider("frontend")
+	users := newProvider("users")
+
+	{ // send data
+		ctx, client := frontend.Tracer("frontend").Start(t.Context(), "GET /users", trace.WithSpanKind(trace.SpanKindClient))
+		_, server := users.Tracer("users").Start(ctx, "GET /users", trace.WithSpanKind(trace.SpanKindServer))
+		server.End()
+		client.End()
+	}
+	require.NoError(t, frontend.Shutdown(t.Context()))
+	require.NoError(t, users.Shutdown(t.Context()))
+
+	edges, _, err := s.Prometheus.GetServiceGraph(1, 30)
+	require.NoError(t, err, "must be able to get the service graph from prometheus")
+	assert.Equal(t, "frontend", edges[0].Client)
+	assert.Equal(t, "users", edges[0].Server)
+	assert.InDelta(t, 1.0, edges[0].Requests, 0)
+
+	dependencies, _, err := s.Jaeger.GetDependencies(1, 30, time.Hour)
+	require.NoError(t, err, "must be able to get the dependencies from jaeger")
+	assert.Equal(t, []backend.Edge{{Client: "frontend", Server: "users", Requests: 1}}, dependencies)
+}
+
+func TestFaultProxy(t *testing.T) {
+	s := New(false, false, true, WithFaultProxy(), WithCapture())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	_, port, err := net.SplitHostPort(s.Proxy.GRPCAddr)
+	require.NoError(t, err, "the proxy must be listening")
+
+	s.Proxy.Inject(proxy.Unavailable(0).Times(1))
+	shutdownOTEL := setupOTELgRPC(t, false, false, true, nat.Port(port))
+
+	{ // send data
+		_, span := otel.Tracer(serviceName).Start(t.Context(), "retried-span")
+		span.End()
+	}
+	shutdownOTEL()
+
+	stats := s.Proxy.Stats()
+	assert.Equal(t, int64(1), stats.Faulted, "the first export must be refused")
+	assert.Equal(t, int64(1), stats.Forwarded, "the exporter must retry the refused export")
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		telemetry, err := s.Collector.Captured(t.Context())
+		require.NoError(c, err, "must be able to read the captured telemetry")
+		require.Len(c, telemetry.Spans(), 1)
+	}, time.Second*10, time.Millisecond*200)
+}
+
+func TestTempoAndZipkin(t *testing.T) {
+	t.Parallel()
+	s := New(false, false, true, WithTempo(), WithZipkin())
+	_, err := s.Start(t.Context())
+	require.ErrorContains(t, err, "Tempo and Zipkin", "must fail before starting any container")
+}
+
+func TestVictoriaMetricsAndPrometheusOTLP(t *testing.T) {
+	t.Parallel()
+	s := New(true, false, false, WithVictoriaMetrics(false), WithPrometheusOTLP(prometheus.UnderscoreEscapingWithSuffixes))
+	_, err := s.Start(t.Context())
+	require.ErrorContains(t, err, "VictoriaMetrics", "must fail before starting any container")
+}
+
+func TestFaultProxyTLS(t *testing.T) {
+	t.Parallel()
+	s := New(false, false, true, WithFaultProxy(), WithTLS(false))
+	_, err := s.Start(t.Context())
+	require.ErrorContains(t, err, "fault proxy", "must fail before starting any container")
+}
+
+func TestInMemory(t *testing.T) {
+	s := New(false, true, true, WithInMemory())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up without docker")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	shutdownOTEL := setupOTELgRPC(t, false, true, true, s.Collector.Ports[4317])
+
+	{ // send data
+		_, span := otel.Tracer(serviceName).Start(t.Context(), "in-memory-span")
+		span.End()
+
+		record := log.Record{}
+		record.SetTimestamp(time.Now())
+		record.SetBody(log.StringValue("test message"))
+		otelLogGlobal.GetLoggerProvider().
+			Logger(serviceName).
+			Emit(t.Context(), record)
+	}
+	shutdownOTEL()
+
+	traces, _, err := s.Jaeger.GetTraces(1, 5, serviceName)
+	require.NoError(t, err, "must be able to get traces")
+	require.Len(t, traces, 1)
+	assert.Equal(t, "in-memory-span", traces[0].Spans[0].OperationName)
+
+	events, _, err := s.Seq.GetEvents(1, 5)
+	require.NoError(t, err, "must be able to get events")
+	require.Len(t, events, 1)
+	assert.Equal(t, "test message", events[0].Messages[0].Text)
+}
+
+func TestInMemoryUnsupported(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]Option{
+		"tls":              WithTLS(false),
+		"bearer token":     WithBearerToken("s3cret"),
+		"metadata headers": WithMetadataHeaders("X-Tenant"),
+		"processors":       func(s *Stack) { s.Collector.Processors = []collector.Processor{collector.Batch{}} },
+		"span metrics":     WithSpanMetrics(),
+		"service graph":    WithServiceGraph(),
+		"capture":          WithCapture(),
+		"tempo":            WithTempo(),
+	}
+	for name, option := range testData {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := New(true, true, true, WithInMemory(), option)
+			_, err := s.Start(t.Context())
+			require.Error(t, err, "the in-memory backend must not silently ignore the option")
+		})
+	}
+}
+
+func TestLGTMUnsupported(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]Option{
+		"bearer token":  WithBearerToken("s3cret"),
+		"capture":       WithCapture(),
+		"processors":    func(s *Stack) { s.Collector.Processors = []collector.Processor{collector.Batch{}} },
+		"span metrics":  WithSpanMetrics(),
+		"service graph": WithServiceGraph(),
+		"tempo":         WithTempo(),
+		"loki":          WithLoki(),
+	}
+	for name, option := range testData {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := New(true, true, true, WithLGTM(), option)
+			_, err := s.Start(t.Context())
+			require.Error(t, err, "the lgtm container must not silently ignore the option")
+		})
+	}
+}
+
+type fakeTraceBackend struct{ traces []backend.Trace }
+
+func (f fakeTraceBackend) Traces(int, int, string) ([]backend.Trace, error) {
+	return f.traces, nil
+}
+
+func TestTempo(t *testing.T) {
+	s := New(false, false, true, WithTempo())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	shutdownOTEL := setupOTELgRPC(t, false, false, true, s.Collector.Ports[4317])
+
+	{ // send data
+		_, span := otel.Tracer(serviceName).Start(t.Context(), "tempo-span")
+		span.SetStatus(codes.Error, "failed")
+		span.End()
+	}
+	shutdownOTEL()
+
+	traces, err := s.TraceBackend().Traces(1, 30, serviceName)
+	require.NoError(t, err, "must be able to get the traces from tempo")
+	require.Len(t, traces, 1)
+	assert.Equal(t, "tempo-span", traces[0].Spans[0].Name)
+
+	traces, err = s.Tempo.Search(t.Context(), fmt.Sprintf(`{ resource.service.name = %q && status = error }`, serviceName), time.Hour)
+	require.NoError(t, err, "must be able to search with traceql")
+	assert.Len(t, traces, 1)
+}
+
+func TestLoki(t *testing.T) {
+	s := New(false, true, false, WithLoki())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	shutdownOTEL := setupOTELgRPC(t, false, true, false, s.Collector.Ports[4317])
+
+	{ // send data
+		record := log.Record{}
+		record.SetTimestamp(time.Now())
+		record.SetBody(log.StringValue("loki message"))
+		record.SetSeverity(log.SeverityError)
+		record.SetSeverityText("ERROR")
+		record.AddAttributes(log.String("user.id", "42"))
+		otelLogGlobal.GetLoggerProvider().
+			Logger(serviceName).
+			Emit(t.Context(), record)
+	}
+	shutdownOTEL()
+
+	records, err := s.LogBackend().LogRecords(1, 30)
+	require.NoError(t, err, "must be able to get the log records from loki")
+	assert.Equal(t, "loki message", records[0].Body)
+	assert.Equal(t, "ERROR", records[0].Level)
+
+	end := time.Now()
+	streams, err := s.Loki.QueryRange(t.Context(), fmt.Sprintf(`{service_name=%q} | user_id="42"`, serviceName), end.Add(-time.Hour), end)
+	require.NoError(t, err, "must be able to query with logql")
+	require.Len(t, streams, 1)
+	assert.Equal(t, "42", streams[0].Entries[0].StructuredMetadata["user_id"])
+}
+
+func TestLGTM(t *testing.T) {
+	s := New(false, true, true, WithLGTM())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+	assert.Same(t, s.Tempo, s.TraceBackend())
+	assert.Same(t, s.Loki, s.LogBackend())
+
+	shutdownOTEL := setupOTELgRPC(t, false, true, true, s.Collector.Ports[4317])
+
+	{ // send data
+		_, span := otel.Tracer(serviceName).Start(t.Context(), "lgtm-span")
+		span.End()
+
+		record := log.Record{}
+		record.SetTimestamp(time.Now())
+		record.SetBody(log.StringValue("lgtm message"))
+		otelLogGlobal.GetLoggerProvider().
+			Logger(serviceName).
+			Emit(t.Context(), record)
+	}
+	shutdownOTEL()
+
+	traces, err := s.TraceBackend().Traces(1, 30, serviceName)
+	require.NoError(t, err, "must be able to get the traces from tempo")
+	assert.Equal(t, "lgtm-span", traces[0].Spans[0].Name)
+
+	records, err := s.LogBackend().LogRecords(1, 30)
+	require.NoError(t, err, "must be able to get the log records from loki")
+	assert.Equal(t, "lgtm message", records[0].Body)
+}
+
+func TestAspire(t *testing.T) {
+	s := New(false, false, true, WithAspire())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	shutdownOTEL := setupOTELgRPC(t, false, false, true, s.Collector.Ports[4317])
+
+	{ // send data
+		_, span := otel.Tracer(serviceName).Start(t.Context(), "aspire-span")
+		span.End()
+	}
+	shutdownOTEL()
+
+	traces, _, err := s.Jaeger.GetTraces(1, 30, serviceName)
+	require.NoError(t, err, "the traces must still reach jaeger")
+	require.Len(t, traces, 1)
+
+	resp, err := http.Get(s.Aspire.LoginURL())
+	require.NoError(t, err, "must be able to log into aspire")
+	assert.Equal(t, 200, resp.StatusCode, "request should be 200")
+}
+
+func TestZipkin(t *testing.T) {
+	s := New(false, false, true, WithZipkin(), WithZipkinReceiver())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	shutdownOTEL := setupOTELgRPC(t, false, false, true, s.Collector.Ports[4317])
+
+	{ // send data
+		_, span := otel.Tracer(serviceName).Start(t.Context(), "otlp-span")
+		span.End()
+
+		legacySpan := fmt.Sprintf(`[{"traceId":"5af7183fb1d4cf5f","id":"6b221d5bc9e6496c","name":"legacy-span",`+
+			`"timestamp":%d,"duration":1000,"localEndpoint":{"serviceName":%q}}]`, time.Now().UnixMicro(), serviceName)
+		resp, err := http.Post(s.Collector.Endpoint(9411)+"/api/v2/spans", "application/json", strings.NewReader(legacySpan))
+		require.NoError(t, err, "must be able to send zipkin spans to the collector")
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	}
+	shutdownOTEL()
+
+	traces, err := s.TraceBackend().Traces(2, 30, serviceName)
+	require.NoError(t, err, "must be able to get the traces from zipkin")
+	names := []string{traces[0].Spans[0].Name, traces[1].Spans[0].Name}
+	assert.ElementsMatch(t, []string{"otlp-span", "legacy-span"}, names)
+}
+
+func TestPrometheusOTLP(t *testing.T) {
+	s := New(true, false, false, WithPrometheusOTLP(prometheus.UnderscoreEscapingWithSuffixes))
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+
+	shutdownOTEL := setupOTELgRPC(t, true, false, false, s.Collector.Ports[4317])
+	t.Cleanup(shutdownOTEL)
+
+	startGoroutineMeter(t)
+
+	metrics, _, err := s.Prometheus.GetMetrics(3, 30, "goroutine_count", serviceName, time.Second*30)
+	require.NoError(t, err, "must be able to get the metrics pushed over otlp")
+	assert.GreaterOrEqual(t, len(metrics.Values), 3)
+}
+
+func TestVictoriaMetrics(t *testing.T) {
+	for _, otlp := range []bool{false, true} {
+		t.Run(fmt.Sprintf("otlp %t", otlp), func(t *testing.T) {
+			s := New(true, false, false, WithVictoriaMetrics(otlp))
+			shutdownStack, err := s.Start(t.Context())
+			require.NoError(t, err, "the stack must start up")
+			t.Cleanup(func() {
+				if err := shutdownStack(context.Background()); err != nil {
+					t.Logf("error shutting down stack: %v", err)
+				}
+			})
+
+			shutdownOTEL := setupOTELgRPC(t, true, false, false, s.Collector.Ports[4317])
+			t.Cleanup(shutdownOTEL)
+
+			startGoroutineMeter(t)
+
+			series, err := s.MetricBackend().Series(3, 30, "goroutine_count", serviceName, time.Second*30)
+			require.NoError(t, err, "must be able to get the metrics from victoriametrics")
+			assert.GreaterOrEqual(t, len(series.Points), 3)
+
+			end := time.Now()
+			metrics, err := s.VictoriaMetrics.QueryRange(t.Context(),
+				fmt.Sprintf(`max_over_time(goroutine_count{service_name=%q}[30s])`, serviceName), end.Add(-time.Minute), end, time.Second*10)
+			require.NoError(t, err, "must be able to run a metricsql query")
+			assert.NotEmpty(t, metrics)
+		})
+	}
+}
+
+func TestGrafana(t *testing.T) {
+	s := New(true, false, true, WithGrafana(), WithTempo())
+	shutdownStack, err := s.Start(t.Context())
+	require.NoError(t, err, "the stack must start up")
+	t.Cleanup(func() {
+		if err := shutdownStack(context.Background()); err != nil {
+			t.Logf("error shutting down stack: %v", err)
+		}
+	})
+	s.LogExploreURLs(t)
+
+	var names []string
+	for _, d := range s.Grafana.ProvisionedDatasources() {
+		names = append(names, d.Name)
+	}
+	assert.Equal(t, []string{"Tempo", "Prometheus"}, names)
+
+	resp, err := http.Get(s.Grafana.Endpoint(3000) + "/api/datasources/uid/tempo")
+	require.NoError(t, err, "must be able to call grafana")
+	assert.Equal(t, 200, resp.StatusCode, "the tempo datasource must be provisioned")
+}
+
+func TestExporterConflict(t *testing.T) {
+	t.Parallel()
+
+	s := New(false, false, true, WithTempo())
+	s.Collector.Exporters = map[collector.Signal]collector.Exporter{collector.Traces: collector.OTLPExporter("otlp/mine", "mine:4317")}
+	_, err := s.Start(t.Context())
+	require.Error(t, err, "the stack must not replace a traces exporter that was set by the user")
+
+	s = New(false, false, true, WithTempo())
+	s.setExporter(collector.Traces, collector.OTLPExporter(tempoExporter, "tempo:4317"))
+	require.NoError(t, s.checkExporters(), "the stack's own exporter must not conflict when the stack is started again")
+}
+
+func TestSetExtraExporter(t *testing.T) {
+	t.Parallel()
+
+	s := New(false, false, true, WithAspire())
+	s.Collector.ExtraExporters = []collector.Exporter{collector.OTLPExporter("otlp/mine", "mine:4317")}
+	s.setExtraExporter(collector.OTLPExporter(aspireExporter, "aspire:18889"))
+	s.setExtraExporter(collector.OTLPExporter(aspireExporter, "aspire:18889"))
+
+	require.Len(t, s.Collector.ExtraExporters, 2, "starting the stack again must not add the aspire exporter twice")
+	assert.Equal(t, aspireExporter, s.Collector.ExtraExporters[1].Name)
+}
+
+func TestOptionOrder(t *testing.T) {
+	t.Parallel()
+
+	r := &registry.Config{Prefix: "mirror.internal"}
+	s := New(true, true, true, WithRegistry(r), WithAliasPrefix("a"), WithTempo(), WithLoki(), WithGrafana())
+
+	assert.Same(t, r, s.Collector.Registry)
+	assert.Same(t, r, s.Tempo.Registry, "the registry must apply to backends added after it")
+	assert.Same(t, r, s.Loki.Registry)
+	assert.Same(t, r, s.Grafana.Registry)
+	assert.Equal(t, "a-collector", s.Collector.Alias)
+	assert.Equal(t, "a-tempo", s.Tempo.Alias, "the prefix must apply to backends added after it")
+	assert.Equal(t, "a-loki", s.Loki.Alias)
+	assert.Equal(t, "a-grafana", s.Grafana.Alias)
+}
+
+func TestBackends(t *testing.T) {
+	t.Parallel()
+
+	s := New(true, true, true)
+	assert.Same(t, &s.Jaeger, s.TraceBackend())
+	assert.Same(t, &s.Seq, s.LogBackend())
+	assert.Same(t, &s.Prometheus, s.MetricBackend())
+
+	s = New(false, false, true, WithTempo(), WithAliasPrefix("a"))
+	assert.Same(t, s.Tempo, s.TraceBackend())
+	assert.Equal(t, "a-tempo", s.Tempo.Alias)
+
+	s = New(false, false, true, WithZipkin(), WithAliasPrefix("a"))
+	assert.Same(t, s.Zipkin, s.TraceBackend())
+	assert.Equal(t, "a-zipkin", s.Zipkin.Alias)
+
+	s = New(true, false, false, WithVictoriaMetrics(true), WithAliasPrefix("a"))
+	assert.Same(t, s.VictoriaMetrics, s.MetricBackend())
+	assert.Equal(t, "a-victoriametrics", s.VictoriaMetrics.Alias)
+
+	s = New(false, true, false, WithLoki(), WithAliasPrefix("a"))
+	assert.Same(t, s.Loki, s.LogBackend())
+	assert.Equal(t, "a-loki", s.Loki.Alias)
+
+	s = New(true, false, true, WithGrafana(), WithAliasPrefix("a"))
+	assert.Equal(t, "a-grafana", s.Grafana.Alias)
+	assert.Equal(t, []grafana.Datasource{
+		{Name: "Jaeger", Type: "jaeger", UID: "jaeger", URL: "http://a-jaeger:16686"},
+		{Name: "Prometheus", Type: "prometheus", UID: "prometheus", URL: "http://a-prometheus:9090"},
+	}, s.grafanaDatasources())
+
+	fake := fakeTraceBackend{traces: []backend.Trace{{TraceID: "0a"}}}
+	s = New(true, true, true, WithTraceBackend(fake))
+	traces, err := s.TraceBackend().Traces(1, 1, serviceName)
+	require.NoError(t, err)
+	assert.Equal(t, fake.traces, traces)
+}
@@ -0,0 +1,50 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+)
+
+var _ backend.MetricBackend = (*Prometheus)(nil)
+
+// Series implements backend.MetricBackend, returning the same series as GetMetrics in a backend-neutral form.
+func (p *Prometheus) Series(expectedPoints int, maxRetries int, metricName string, service string, since time.Duration) (backend.Series, error) {
+	metrics, _, err := p.GetMetrics(expectedPoints, maxRetries, metricName, service, since)
+	series, convertErr := neutralSeries(metrics)
+	if err != nil {
+		return series, err
+	}
+	return series, convertErr
+}
+
+func neutralSeries(metrics Metrics) (backend.Series, error) {
+	series := backend.Series{Name: metrics.Metric["__name__"], Labels: map[string]string{}}
+	for k, v := range metrics.Metric {
+		if k != "__name__" {
+			series.Labels[k] = v
+		}
+	}
+
+	for _, v := range metrics.Values {
+		if len(v) != 2 {
+			return series, fmt.Errorf("prometheus: malformed sample %v", v)
+		}
+		timestamp, ok := v[0].(float64)
+		if !ok {
+			return series, fmt.Errorf("prometheus: malformed sample timestamp %v", v[0])
+		}
+		value, err := sampleValue(v)
+		if err != nil {
+			return series, err
+		}
+		series.Points = append(series.Points, backend.Point{
+			Time:  time.UnixMilli(int64(math.Round(timestamp * 1000))),
+			Value: value,
+		})
+	}
+
+	return series, nil
+}
@@ -0,0 +1,49 @@
+package prometheus
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeries(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" || r.URL.Query().Get("query") != `goroutine_count{service_name="api"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{
+			"metric":{"__name__":"goroutine_count","service_name":"api"},
+			"values":[[1700000000.123,"4"],[1700000010.123,"6.5"]]
+		}]}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	p := Prometheus{Host: "127.0.0.1", Ports: map[int]nat.Port{9090: nat.Port(port)}}
+
+	series, err := p.Series(2, 1, "goroutine_count", "api", time.Minute)
+	require.NoError(t, err, "must be able to get the series")
+	assert.Equal(t, backend.Series{
+		Name:   "goroutine_count",
+		Labels: map[string]string{"service_name": "api"},
+		Points: []backend.Point{
+			{Time: time.UnixMilli(1700000000123), Value: 4},
+			{Time: time.UnixMilli(1700000010123), Value: 6.5},
+		},
+	}, series)
+
+	_, err = p.Series(3, 1, "goroutine_count", "api", time.Minute)
+	require.Error(t, err, "must fail when the expected points are not returned")
+}
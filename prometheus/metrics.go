@@ -31,8 +31,6 @@ type requestStruct struct {
 	Step  string `url:"step,omitempty"`
 }
 
-var errRespCode = fmt.Errorf("the return was not of status 200")
-
 // GetMetrics takes in a service names and returns the last n `metricName` events corresponding to that `service` over that `since`.
 // There is a retry mechanism implemented; `GetMetrics` will keep fetching every 2 seconds, for a maximum
 // of `maxRetries` times, until Prometheus returns `expectedDataPoints` number of metrics points.
@@ -50,7 +48,7 @@ func (p *Prometheus) GetMetrics(expectedDataPoints int, maxRetries int, metricNa
 
 		sinceStart := time.Since(startTime)
 		r := requestStruct{
-			Query: fmt.Sprintf("%s{service_name=\"%s\"}", metricName, service),
+			Query: p.selector(metricName, service),
 			Start: time.Now().Add(-since - sinceStart).Format(time.RFC3339),
 			End:   time.Now().Format(time.RFC3339),
 			Step:  "10s",
@@ -61,11 +59,11 @@ func (p *Prometheus) GetMetrics(expectedDataPoints int, maxRetries int, metricNa
 			return metrics, "", fmt.Errorf("prometheus: could not marshal values into a url query for request %v: %w", r, queryErr)
 		}
 
-		endpoint = fmt.Sprintf("http://localhost:%d/api/v1/query_range?%s", p.Ports[9090].Int(), v.Encode())
+		endpoint = fmt.Sprintf("http://%s:%d/api/v1/query_range?%s", p.host(), p.Ports[9090].Int(), v.Encode())
 
 		var u unmarshalStruct
 		err := request.Request(endpoint, &u)
-		if err != nil && !errors.Is(err, errRespCode) {
+		if err != nil && !errors.Is(err, request.ErrRetryableCode) {
 			return metrics, endpoint, fmt.Errorf("prometheus: request returned a non-retryable error: %w", err)
 		}
 
@@ -0,0 +1,49 @@
+package prometheus
+
+import "fmt"
+
+// TranslationStrategy is how Prometheus translates the names of OTLP metrics and attributes.
+type TranslationStrategy string
+
+const (
+	// UnderscoreEscapingWithSuffixes replaces the characters that are invalid in classic Prometheus names with
+	// underscores, and adds the unit and type suffixes (e.g. http.server.duration becomes
+	// http_server_duration_seconds_bucket). It is Prometheus' default, and matches the collector's prometheus exporter.
+	UnderscoreEscapingWithSuffixes TranslationStrategy = "UnderscoreEscapingWithSuffixes"
+	// NoUTF8EscapingWithSuffixes keeps the UTF-8 names, and only adds the unit and type suffixes
+	// (e.g. http.server.duration becomes http.server.duration_seconds_bucket, with a service.name label).
+	NoUTF8EscapingWithSuffixes TranslationStrategy = "NoUTF8EscapingWithSuffixes"
+)
+
+// OTLP makes Prometheus receive metrics over OTLP on /api/v1/otlp, rather than scraping the collector's prometheus
+// exporter, which removes the scrape delay and keeps exemplars. TranslationStrategy defaults to
+// UnderscoreEscapingWithSuffixes.
+type OTLP struct {
+	TranslationStrategy TranslationStrategy
+}
+
+// OTLPEndpoint returns the URL that the collector's otlphttp exporter pushes metrics to on the stack network,
+// to which the exporter appends /v1/metrics.
+func (p *Prometheus) OTLPEndpoint() string {
+	alias := p.Alias
+	if alias == "" {
+		alias = DefaultAlias
+	}
+	return fmt.Sprintf("http://%s:9090/api/v1/otlp", alias)
+}
+
+func (o *OTLP) translationStrategy() TranslationStrategy {
+	if o.TranslationStrategy == "" {
+		return UnderscoreEscapingWithSuffixes
+	}
+	return o.TranslationStrategy
+}
+
+// selector returns the PromQL selector of the metric's series for the service, quoting the names when they
+// are kept as UTF-8.
+func (p *Prometheus) selector(metricName string, service string) string {
+	if p.OTLP != nil && p.OTLP.translationStrategy() == NoUTF8EscapingWithSuffixes {
+		return fmt.Sprintf(`{%q, "service.name"=%q}`, metricName, service)
+	}
+	return fmt.Sprintf("%s{service_name=%q}", metricName, service)
+}
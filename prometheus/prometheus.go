@@ -7,23 +7,40 @@ import (
 	"strings"
 	"time"
 
+	"github.com/adreasnow/otelstack/registry"
 	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+// DefaultImage is the image used for the Prometheus container.
+const DefaultImage = "prom/prometheus:v3.2.1"
+
+// DefaultAlias is the network alias that other containers use to reach Prometheus.
+const DefaultAlias = "prometheus"
+
 // Prometheus holds the testcontainer, ports and network used by Jaeger. If instantiating yourself,
 // be sure to populate Jaeger.Network, otherwise a new network will be generated.
+// Host is the hostname that the ports are reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
+// OTLP makes Prometheus receive metrics over OTLP instead of scraping the collector, see OTLP.
 type Prometheus struct {
-	Ports   map[int]nat.Port
-	Network *testcontainers.DockerNetwork
-	Name    string
-	config  string
+	Ports    map[int]nat.Port
+	Network  *testcontainers.DockerNetwork
+	Host     string
+	Image    string
+	Registry *registry.Config
+	Alias    string
+	Name     string
+	OTLP     *OTLP
+	config   string
 }
 
-// Start starts the Prometheus container.
-func (p *Prometheus) Start(ctx context.Context, collectorName string) (func(context.Context) error, error) {
+// Start starts the Prometheus container, scraping the collector through the given network alias, or receiving
+// metrics over OTLP when OTLP is set.
+func (p *Prometheus) Start(ctx context.Context, collectorAlias string) (func(context.Context) error, error) {
 	emptyFunc := func(context.Context) error { return nil }
 	var err error
 
@@ -36,21 +53,32 @@ func (p *Prometheus) Start(ctx context.Context, collectorName string) (func(cont
 		}
 	}
 
-	p.generateConfig(collectorName)
+	p.generateConfig(collectorAlias)
+
+	if p.Alias == "" {
+		p.Alias = DefaultAlias
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          p.image(),
+		ExposedPorts:   []string{"9090/tcp"},
+		Networks:       []string{p.Network.Name},
+		NetworkAliases: map[string][]string{p.Network.Name: {p.Alias}},
+		WaitingFor:     wait.ForLog("Server is ready to receive web requests."),
+		Files: []testcontainers.ContainerFile{{
+			ContainerFilePath: "/etc/prometheus/prometheus.yml",
+			Reader:            strings.NewReader(p.config),
+			FileMode:          0644,
+		}},
+	}
+	req.Cmd = p.Command()
+	if err := p.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("prometheus: could not prepare the image: %w", err)
+	}
 
 	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: testcontainers.ContainerRequest{
-			Image:        "prom/prometheus:v3.2.1",
-			ExposedPorts: []string{"9090/tcp"},
-			Networks:     []string{p.Network.Name},
-			WaitingFor:   wait.ForLog("Server is ready to receive web requests."),
-			Files: []testcontainers.ContainerFile{{
-				ContainerFilePath: "/etc/prometheus/prometheus.yml",
-				Reader:            strings.NewReader(p.config),
-				FileMode:          0644,
-			}},
-		},
-		Started: true,
+		ContainerRequest: req,
+		Started:          true,
 	})
 	if err != nil {
 		return emptyFunc, fmt.Errorf("prometheus: could not start the testcontainer: %w", err)
@@ -60,7 +88,7 @@ func (p *Prometheus) Start(ctx context.Context, collectorName string) (func(cont
 	if err != nil {
 		return emptyFunc, fmt.Errorf("prometheus: could not read the name of the container from the testcontainer: %w", err)
 	}
-	p.Name = p.Name[1:]
+	p.Name = strings.TrimPrefix(p.Name, "/")
 
 	for _, portNum := range []int{9090} {
 		p.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
@@ -74,18 +102,47 @@ func (p *Prometheus) Start(ctx context.Context, collectorName string) (func(cont
 	}, nil
 }
 
-func (p *Prometheus) generateConfig(collectorName string) {
-	p.config = fmt.Sprintf(`
-global:
-  scrape_interval: 2s
-  evaluation_interval: 2s
+// Command returns the command that the container is started with, which is the image's default unless OTLP is set.
+func (p *Prometheus) Command() []string {
+	if p.OTLP == nil {
+		return nil
+	}
+	// replaces the image's default command, so the config and storage flags are repeated
+	return []string{
+		"--config.file=/etc/prometheus/prometheus.yml",
+		"--storage.tsdb.path=/prometheus",
+		"--web.enable-otlp-receiver",
+		"--enable-feature=exemplar-storage",
+	}
+}
 
+// Config returns the Prometheus configuration that is generated for the given collector alias, which is
+// not scraped when OTLP is set.
+func (p *Prometheus) Config(collectorAlias string) string {
+	p.generateConfig(collectorAlias)
+	return p.config
+}
+
+func (p *Prometheus) generateConfig(collectorAlias string) {
+	scrapeConfigs := fmt.Sprintf(`
 scrape_configs:
   - job_name: otel
     static_configs:
       - targets: ["%s:8889"]
+`, collectorAlias)
+	translationStrategy := UnderscoreEscapingWithSuffixes
+	if p.OTLP != nil {
+		scrapeConfigs = ""
+		translationStrategy = p.OTLP.translationStrategy()
+	}
 
+	p.config = fmt.Sprintf(`
+global:
+  scrape_interval: 2s
+  evaluation_interval: 2s
+%s
 otlp:
+  translation_strategy: %s
   keep_identifying_resource_attributes: true
   # Recommended attributes to be promoted to labels.
   promote_resource_attributes:
@@ -97,5 +154,24 @@ otlp:
 storage:
   tsdb:
     out_of_order_time_window: 10m
-`, collectorName)
+`, scrapeConfigs, translationStrategy)
+}
+
+// Endpoint returns the URL that the given container port of Prometheus can be reached on.
+func (p *Prometheus) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", p.host(), p.Ports[port].Int())
+}
+
+func (p *Prometheus) host() string {
+	if p.Host == "" {
+		return "localhost"
+	}
+	return p.Host
+}
+
+func (p *Prometheus) image() string {
+	if p.Image == "" {
+		return DefaultImage
+	}
+	return p.Image
 }
@@ -16,6 +16,29 @@ func TestGenerateConfig(t *testing.T) {
 	p.generateConfig("collector")
 
 	assert.Contains(t, p.config, `- targets: ["collector:8889"]`)
+	assert.Contains(t, p.config, "translation_strategy: UnderscoreEscapingWithSuffixes")
+}
+
+func TestGenerateConfigOTLP(t *testing.T) {
+	t.Parallel()
+	p := Prometheus{OTLP: &OTLP{TranslationStrategy: NoUTF8EscapingWithSuffixes}}
+	p.generateConfig("collector")
+
+	assert.NotContains(t, p.config, "scrape_configs", "the collector must not be scraped")
+	assert.Contains(t, p.config, "translation_strategy: NoUTF8EscapingWithSuffixes")
+	assert.Equal(t, "http://prometheus:9090/api/v1/otlp", p.OTLPEndpoint())
+}
+
+func TestSelector(t *testing.T) {
+	t.Parallel()
+	p := Prometheus{}
+	assert.Equal(t, `goroutine_count{service_name="api"}`, p.selector("goroutine_count", "api"))
+
+	p.OTLP = &OTLP{}
+	assert.Equal(t, `goroutine_count{service_name="api"}`, p.selector("goroutine_count", "api"))
+
+	p.OTLP.TranslationStrategy = NoUTF8EscapingWithSuffixes
+	assert.Equal(t, `{"goroutine.count", "service.name"="api"}`, p.selector("goroutine.count", "api"))
 }
 
 func TestPrometheusStart(t *testing.T) {
@@ -0,0 +1,87 @@
+package prometheus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+)
+
+// The names of the metrics generated by the collector's servicegraph connector.
+const (
+	ServiceGraphRequests       = "traces_service_graph_request_total"
+	ServiceGraphFailedRequests = "traces_service_graph_request_failed_total"
+)
+
+// GetServiceGraph returns the edges between services, ordered by client and then server. There is a retry
+// mechanism implemented; `GetServiceGraph` will keep fetching every 2 seconds, for a maximum of `maxRetries`
+// times, until Prometheus returns `expectedEdges` edges.
+func (p *Prometheus) GetServiceGraph(expectedEdges int, maxRetries int) ([]backend.Edge, string, error) {
+	var endpoint string
+	var edges []backend.Edge
+
+	var attempts int
+	for {
+		attempts++
+		if attempts > 1 {
+			time.Sleep(time.Second * 2)
+		}
+
+		var err error
+		edges, endpoint, err = p.serviceGraph()
+		if err != nil {
+			return edges, endpoint, err
+		}
+
+		if len(edges) >= expectedEdges {
+			return edges, endpoint, nil
+		}
+
+		if attempts >= maxRetries {
+			return edges, endpoint, fmt.Errorf("prometheus: could not get %d service graph edges in %d attempts", expectedEdges, maxRetries)
+		}
+	}
+}
+
+func (p *Prometheus) serviceGraph() ([]backend.Edge, string, error) {
+	type key struct{ client, server, connectionType string }
+	byKey := map[key]*backend.Edge{}
+
+	var endpoint string
+	for _, metric := range []string{ServiceGraphRequests, ServiceGraphFailedRequests} {
+		var u vectorStruct
+		var err error
+		endpoint, err = p.query(fmt.Sprintf("sum by (client, server, connection_type) (%s)", metric), &u)
+		if err != nil {
+			return nil, endpoint, err
+		}
+
+		for _, r := range u.Data.Result {
+			value, err := sampleValue(r.Value)
+			if err != nil {
+				return nil, endpoint, err
+			}
+
+			k := key{r.Metric["client"], r.Metric["server"], r.Metric["connection_type"]}
+			edge, ok := byKey[k]
+			if !ok {
+				edge = &backend.Edge{Client: k.client, Server: k.server, ConnectionType: k.connectionType}
+				byKey[k] = edge
+			}
+
+			if metric == ServiceGraphRequests {
+				edge.Requests = value
+			} else {
+				edge.Failed = value
+			}
+		}
+	}
+
+	edges := make([]backend.Edge, 0, len(byKey))
+	for _, edge := range byKey {
+		edges = append(edges, *edge)
+	}
+	backend.SortEdges(edges)
+
+	return edges, endpoint, nil
+}
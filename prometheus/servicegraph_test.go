@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetServiceGraph(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result string
+		switch q := r.URL.Query().Get("query"); {
+		case strings.Contains(q, ServiceGraphFailedRequests):
+			result = `{"metric":{"client":"frontend","server":"users","connection_type":""},"value":[1,"1"]}`
+		case strings.Contains(q, ServiceGraphRequests):
+			result = `{"metric":{"client":"users","server":"postgres","connection_type":"database"},"value":[1,"2"]},` +
+				`{"metric":{"client":"frontend","server":"users","connection_type":""},"value":[1,"3"]}`
+		}
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[%s]}}`, result)
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	p := Prometheus{Host: "127.0.0.1", Ports: map[int]nat.Port{9090: nat.Port(port)}}
+
+	edges, _, err := p.GetServiceGraph(2, 1)
+	require.NoError(t, err, "must be able to get the service graph")
+	assert.Equal(t, []backend.Edge{
+		{Client: "frontend", Server: "users", Requests: 3, Failed: 1},
+		{Client: "users", Server: "postgres", ConnectionType: "database", Requests: 2},
+	}, edges)
+
+	_, _, err = p.GetServiceGraph(3, 1)
+	require.Error(t, err, "must fail when the expected edges are not returned")
+}
@@ -0,0 +1,182 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/request"
+)
+
+// The names of the metrics generated by the collector's spanmetrics connector.
+const (
+	SpanMetricsCalls    = "traces_span_metrics_calls_total"
+	SpanMetricsDuration = "traces_span_metrics_duration_milliseconds"
+)
+
+// The span status codes, as labelled by the spanmetrics connector.
+const (
+	StatusUnset = "STATUS_CODE_UNSET"
+	StatusOK    = "STATUS_CODE_OK"
+	StatusError = "STATUS_CODE_ERROR"
+)
+
+// SpanMetrics holds the request rate, errors and duration (RED) metrics derived from spans by the collector's
+// spanmetrics connector, summed over every series that matches the query.
+type SpanMetrics struct {
+	// Calls is the number of spans.
+	Calls float64
+	// Errors is the number of spans with an error status.
+	Errors float64
+	// DurationCount and DurationSum are the count and sum (in milliseconds) of the duration histogram.
+	DurationCount float64
+	DurationSum   float64
+	// Buckets are the cumulative counts of the duration histogram, ordered by their upper bound.
+	Buckets []Bucket
+}
+
+// Bucket is a single bucket of a cumulative histogram.
+type Bucket struct {
+	// UpperBound is in milliseconds, and is +Inf for the last bucket.
+	UpperBound float64
+	Count      float64
+}
+
+// MeanDuration returns the mean duration of the spans.
+func (s SpanMetrics) MeanDuration() time.Duration {
+	if s.DurationCount == 0 {
+		return 0
+	}
+	return time.Duration(s.DurationSum / s.DurationCount * float64(time.Millisecond))
+}
+
+type vectorStruct struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []any             `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// GetSpanMetrics returns the span metrics of the given service and span name, filtered by status (one of StatusUnset,
+// StatusOK or StatusError) unless it is empty. There is a retry mechanism implemented; `GetSpanMetrics` will keep fetching
+// every 2 seconds, for a maximum of `maxRetries` times, until Prometheus has counted `expectedCalls` calls.
+func (p *Prometheus) GetSpanMetrics(expectedCalls int, maxRetries int, service string, spanName string, status string) (SpanMetrics, string, error) {
+	selector := fmt.Sprintf(`service_name=%q,span_name=%q`, service, spanName)
+	if status != "" {
+		selector += fmt.Sprintf(`,status_code=%q`, status)
+	}
+
+	var endpoint string
+	var metrics SpanMetrics
+
+	var attempts int
+	for {
+		attempts++
+		if attempts > 1 {
+			time.Sleep(time.Second * 2)
+		}
+
+		var err error
+		metrics, endpoint, err = p.spanMetrics(selector)
+		if err != nil {
+			return metrics, endpoint, err
+		}
+
+		if metrics.Calls >= float64(expectedCalls) {
+			return metrics, endpoint, nil
+		}
+
+		if attempts >= maxRetries {
+			return metrics, endpoint, fmt.Errorf("prometheus: could not get %d span metric calls in %d attempts", expectedCalls, maxRetries)
+		}
+	}
+}
+
+func (p *Prometheus) spanMetrics(selector string) (SpanMetrics, string, error) {
+	var metrics SpanMetrics
+
+	queries := []struct {
+		query string
+		value *float64
+	}{
+		{fmt.Sprintf("sum(%s{%s})", SpanMetricsCalls, selector), &metrics.Calls},
+		{fmt.Sprintf("sum(%s{%s,status_code=%q})", SpanMetricsCalls, selector, StatusError), &metrics.Errors},
+		{fmt.Sprintf("sum(%s_count{%s})", SpanMetricsDuration, selector), &metrics.DurationCount},
+		{fmt.Sprintf("sum(%s_sum{%s})", SpanMetricsDuration, selector), &metrics.DurationSum},
+	}
+
+	var endpoint string
+	for _, q := range queries {
+		var u vectorStruct
+		var err error
+		endpoint, err = p.query(q.query, &u)
+		if err != nil {
+			return metrics, endpoint, err
+		}
+		if len(u.Data.Result) > 0 {
+			*q.value, err = sampleValue(u.Data.Result[0].Value)
+			if err != nil {
+				return metrics, endpoint, err
+			}
+		}
+	}
+
+	var u vectorStruct
+	endpoint, err := p.query(fmt.Sprintf("sum by (le) (%s_bucket{%s})", SpanMetricsDuration, selector), &u)
+	if err != nil {
+		return metrics, endpoint, err
+	}
+	for _, r := range u.Data.Result {
+		upperBound, err := strconv.ParseFloat(r.Metric["le"], 64)
+		if err != nil {
+			return metrics, endpoint, fmt.Errorf("prometheus: bucket has an invalid upper bound %q: %w", r.Metric["le"], err)
+		}
+		count, err := sampleValue(r.Value)
+		if err != nil {
+			return metrics, endpoint, err
+		}
+		metrics.Buckets = append(metrics.Buckets, Bucket{UpperBound: upperBound, Count: count})
+	}
+	slices.SortFunc(metrics.Buckets, func(a Bucket, b Bucket) int {
+		switch {
+		case a.UpperBound < b.UpperBound:
+			return -1
+		case a.UpperBound > b.UpperBound:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return metrics, endpoint, nil
+}
+
+// query runs an instant query.
+func (p *Prometheus) query(promql string, u *vectorStruct) (string, error) {
+	endpoint := fmt.Sprintf("http://%s:%d/api/v1/query?query=%s", p.host(), p.Ports[9090].Int(), url.QueryEscape(promql))
+	err := request.Request(endpoint, u)
+	if err != nil && !errors.Is(err, request.ErrRetryableCode) {
+		return endpoint, fmt.Errorf("prometheus: request returned a non-retryable error: %w", err)
+	}
+	return endpoint, nil
+}
+
+// sampleValue parses the value of an instant vector sample, which is a [timestamp, "value"] pair.
+func sampleValue(sample []any) (float64, error) {
+	if len(sample) != 2 {
+		return 0, fmt.Errorf("prometheus: malformed sample %v", sample)
+	}
+	s, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("prometheus: malformed sample value %v", sample[1])
+	}
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
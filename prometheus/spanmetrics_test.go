@@ -0,0 +1,69 @@
+package prometheus
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpanMetrics(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("query")
+		mu.Lock()
+		queries = append(queries, q)
+		mu.Unlock()
+
+		var result string
+		switch {
+		case strings.HasPrefix(q, "sum by (le)"):
+			result = `{"metric":{"le":"+Inf"},"value":[1,"4"]},{"metric":{"le":"5"},"value":[1,"1"]},{"metric":{"le":"10"},"value":[1,"3"]}`
+		case strings.Contains(q, SpanMetricsCalls) && strings.Contains(q, StatusError):
+			result = `{"metric":{},"value":[1,"1"]}`
+		case strings.Contains(q, SpanMetricsCalls):
+			result = `{"metric":{},"value":[1,"4"]}`
+		case strings.Contains(q, "_count"):
+			result = `{"metric":{},"value":[1,"4"]}`
+		case strings.Contains(q, "_sum"):
+			result = `{"metric":{},"value":[1,"30"]}`
+		}
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[%s]}}`, result)
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	p := Prometheus{Host: "127.0.0.1", Ports: map[int]nat.Port{9090: nat.Port(port)}}
+
+	metrics, _, err := p.GetSpanMetrics(4, 1, "test service", "GET /", "")
+	require.NoError(t, err, "must be able to get the span metrics")
+
+	assert.InDelta(t, 4.0, metrics.Calls, 0)
+	assert.InDelta(t, 1.0, metrics.Errors, 0)
+	assert.Equal(t, 7500*time.Microsecond, metrics.MeanDuration())
+	require.Len(t, metrics.Buckets, 3)
+	assert.InDelta(t, 5.0, metrics.Buckets[0].UpperBound, 0)
+	assert.InDelta(t, 4.0, metrics.Buckets[2].Count, 0)
+
+	mu.Lock()
+	assert.Contains(t, queries, `sum(traces_span_metrics_calls_total{service_name="test service",span_name="GET /"})`)
+	mu.Unlock()
+
+	_, _, err = p.GetSpanMetrics(5, 1, "test service", "GET /", StatusOK)
+	require.Error(t, err, "must fail when the expected calls are not counted")
+	mu.Lock()
+	assert.Contains(t, queries, `sum(traces_span_metrics_calls_total{service_name="test service",span_name="GET /",status_code="STATUS_CODE_OK"})`)
+	mu.Unlock()
+}
@@ -0,0 +1,373 @@
+// Package proxy holds an in-process OTLP proxy that sits between the app and the collector and injects faults,
+// so that the retry, queueing and back off behaviour of exporters can be tested.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Proxy forwards OTLP gRPC requests to GRPCTarget (host:port) and OTLP HTTP requests to HTTPTarget (a URL),
+// unless a fault has been injected with Inject. The proxy forwards in plaintext, so the collector must not use TLS.
+//
+// GRPCAddr and HTTPAddr are the addresses that the proxy listens on, and are populated by Start.
+type Proxy struct {
+	GRPCTarget string
+	HTTPTarget string
+	GRPCAddr   string
+	HTTPAddr   string
+
+	mu     sync.Mutex
+	faults []Fault
+	conns  map[string]net.Conn
+
+	grpcRequests atomic.Int64
+	httpRequests atomic.Int64
+	faulted      atomic.Int64
+	forwarded    atomic.Int64
+}
+
+// Stats counts the requests that the proxy has seen.
+type Stats struct {
+	GRPCRequests int64
+	HTTPRequests int64
+	// Faulted is the number of requests that had a fault returned instead of being forwarded (including dropped connections).
+	Faulted int64
+	// Forwarded is the number of requests that were forwarded to the collector, regardless of the response.
+	Forwarded int64
+}
+
+// Fault is the behaviour the proxy injects into a request. Latency is added first, then the connection is
+// dropped if Drop is set, otherwise GRPCCode or HTTPStatus is returned if set, otherwise the request is forwarded.
+// RetryAfter is returned as RetryInfo on gRPC errors and as the Retry-After header on HTTP errors.
+//
+// Count is the number of requests the fault applies to before the next injected fault takes over,
+// where 0 applies it until Clear is called.
+type Fault struct {
+	GRPCCode   codes.Code
+	HTTPStatus int
+	RetryAfter time.Duration
+	Latency    time.Duration
+	Drop       bool
+	Count      int
+}
+
+// Unavailable returns UNAVAILABLE to gRPC requests and 503 to HTTP requests.
+func Unavailable(retryAfter time.Duration) Fault {
+	return Fault{GRPCCode: codes.Unavailable, HTTPStatus: http.StatusServiceUnavailable, RetryAfter: retryAfter}
+}
+
+// Throttled returns RESOURCE_EXHAUSTED to gRPC requests and 429 to HTTP requests.
+func Throttled(retryAfter time.Duration) Fault {
+	return Fault{GRPCCode: codes.ResourceExhausted, HTTPStatus: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+// Latency delays requests by d before forwarding them.
+func Latency(d time.Duration) Fault {
+	return Fault{Latency: d}
+}
+
+// DropConnection closes the connection without responding.
+func DropConnection() Fault {
+	return Fault{Drop: true}
+}
+
+// Times returns a copy of the fault that applies to n requests.
+func (f Fault) Times(n int) Fault {
+	f.Count = n
+	return f
+}
+
+// Inject queues faults that are applied to the following requests in order.
+func (p *Proxy) Inject(faults ...Fault) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.faults = append(p.faults, faults...)
+}
+
+// Clear removes every injected fault, so that requests are forwarded again.
+func (p *Proxy) Clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.faults = nil
+}
+
+// Stats returns the counts of the requests that the proxy has seen.
+func (p *Proxy) Stats() Stats {
+	return Stats{
+		GRPCRequests: p.grpcRequests.Load(),
+		HTTPRequests: p.httpRequests.Load(),
+		Faulted:      p.faulted.Load(),
+		Forwarded:    p.forwarded.Load(),
+	}
+}
+
+// GRPCEndpoint returns the URL that exporters can send OTLP gRPC requests to.
+func (p *Proxy) GRPCEndpoint() string {
+	return "http://" + p.GRPCAddr
+}
+
+// HTTPEndpoint returns the URL that exporters can send OTLP HTTP requests to.
+func (p *Proxy) HTTPEndpoint() string {
+	return "http://" + p.HTTPAddr
+}
+
+// next returns the fault for the next request, or nil if it should be forwarded untouched.
+func (p *Proxy) next() *Fault {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.faults) == 0 {
+		return nil
+	}
+
+	f := p.faults[0]
+	if f.Count > 0 {
+		p.faults[0].Count--
+		if p.faults[0].Count == 0 {
+			p.faults = p.faults[1:]
+		}
+	}
+	return &f
+}
+
+// Start listens for gRPC and HTTP requests on random local ports, and returns a function that stops the proxy.
+func (p *Proxy) Start(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+
+	if p.GRPCTarget == "" || p.HTTPTarget == "" {
+		return emptyFunc, fmt.Errorf("proxy: both a gRPC and an HTTP target are required")
+	}
+
+	httpTarget, err := url.Parse(p.HTTPTarget)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("proxy: could not parse the HTTP target %s: %w", p.HTTPTarget, err)
+	}
+
+	conn, err := grpc.NewClient(p.GRPCTarget,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodecV2(rawCodec{})),
+	)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("proxy: could not create a gRPC client for %s: %w", p.GRPCTarget, err)
+	}
+
+	var lc net.ListenConfig
+	grpcListener, err := lc.Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		return emptyFunc, errors.Join(fmt.Errorf("proxy: could not listen for gRPC requests: %w", err), conn.Close())
+	}
+	httpListener, err := lc.Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		return emptyFunc, errors.Join(fmt.Errorf("proxy: could not listen for HTTP requests: %w", err), grpcListener.Close(), conn.Close())
+	}
+
+	p.mu.Lock()
+	p.conns = map[string]net.Conn{}
+	p.mu.Unlock()
+
+	grpcServer := grpc.NewServer(
+		grpc.ForceServerCodecV2(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			return p.handleGRPC(stream, conn)
+		}),
+	)
+	go grpcServer.Serve(&trackingListener{Listener: grpcListener, proxy: p}) //nolint:errcheck
+
+	reverseProxy := httputil.NewSingleHostReverseProxy(httpTarget)
+	httpServer := &http.Server{
+		Handler:           http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { p.handleHTTP(w, r, reverseProxy) }),
+		ReadHeaderTimeout: time.Second * 10,
+	}
+	go httpServer.Serve(httpListener) //nolint:errcheck
+
+	p.GRPCAddr = grpcListener.Addr().String()
+	p.HTTPAddr = httpListener.Addr().String()
+
+	return func(ctx context.Context) error {
+		grpcServer.Stop()
+		return errors.Join(httpServer.Shutdown(ctx), conn.Close())
+	}, nil
+}
+
+func (p *Proxy) handleGRPC(stream grpc.ServerStream, conn *grpc.ClientConn) error {
+	p.grpcRequests.Add(1)
+
+	method, ok := grpc.MethodFromServerStream(stream)
+	if !ok {
+		return status.Error(codes.Internal, "proxy: could not read the method of the request")
+	}
+
+	var in frame
+	if err := stream.RecvMsg(&in); err != nil {
+		return err
+	}
+
+	if f := p.next(); f != nil {
+		time.Sleep(f.Latency)
+
+		if f.Drop {
+			p.faulted.Add(1)
+			if pr, ok := peer.FromContext(stream.Context()); ok {
+				p.closeConn(pr.Addr.String())
+			}
+			return status.Error(codes.Unavailable, "proxy: connection dropped")
+		}
+
+		if f.GRPCCode != codes.OK {
+			p.faulted.Add(1)
+			return grpcError(f.GRPCCode, f.RetryAfter)
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	md = md.Copy()
+	md.Delete("user-agent")
+	ctx := metadata.NewOutgoingContext(stream.Context(), md)
+
+	p.forwarded.Add(1)
+	var out frame
+	var header, trailer metadata.MD
+	err := conn.Invoke(ctx, method, &in, &out, grpc.Header(&header), grpc.Trailer(&trailer))
+	stream.SetTrailer(trailer)
+	if err != nil {
+		return err
+	}
+
+	if err := stream.SetHeader(header); err != nil {
+		return err
+	}
+	return stream.SendMsg(&out)
+}
+
+func grpcError(code codes.Code, retryAfter time.Duration) error {
+	s := status.New(code, fmt.Sprintf("proxy: injected %s", code))
+	if retryAfter > 0 {
+		if detailed, err := s.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+			s = detailed
+		}
+	}
+	return s.Err()
+}
+
+func (p *Proxy) handleHTTP(w http.ResponseWriter, r *http.Request, reverseProxy *httputil.ReverseProxy) {
+	p.httpRequests.Add(1)
+
+	if f := p.next(); f != nil {
+		time.Sleep(f.Latency)
+
+		if f.Drop {
+			p.faulted.Add(1)
+			if hijacker, ok := w.(http.Hijacker); ok {
+				if conn, _, err := hijacker.Hijack(); err == nil {
+					_ = conn.Close()
+					return
+				}
+			}
+			panic(http.ErrAbortHandler)
+		}
+
+		if f.HTTPStatus != 0 {
+			p.faulted.Add(1)
+			if f.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Round(time.Second).Seconds())))
+			}
+			http.Error(w, fmt.Sprintf("proxy: injected %d", f.HTTPStatus), f.HTTPStatus)
+			return
+		}
+	}
+
+	p.forwarded.Add(1)
+	reverseProxy.ServeHTTP(w, r)
+}
+
+// closeConn closes the gRPC connection from the given remote address.
+func (p *Proxy) closeConn(remoteAddr string) {
+	p.mu.Lock()
+	conn, ok := p.conns[remoteAddr]
+	p.mu.Unlock()
+	if ok {
+		_ = conn.Close()
+	}
+}
+
+// trackingListener keeps track of the accepted gRPC connections, so that they can be dropped.
+type trackingListener struct {
+	net.Listener
+	proxy *Proxy
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	l.proxy.mu.Lock()
+	l.proxy.conns[conn.RemoteAddr().String()] = conn
+	l.proxy.mu.Unlock()
+	return &trackedConn{Conn: conn, proxy: l.proxy}, nil
+}
+
+type trackedConn struct {
+	net.Conn
+	proxy *Proxy
+}
+
+func (c *trackedConn) Close() error {
+	c.proxy.mu.Lock()
+	delete(c.proxy.conns, c.RemoteAddr().String())
+	c.proxy.mu.Unlock()
+	return c.Conn.Close()
+}
+
+// frame is a gRPC message that is passed through without being decoded.
+type frame struct {
+	data []byte
+}
+
+// rawCodec passes the gRPC messages through as frames.
+type rawCodec struct{}
+
+var _ encoding.CodecV2 = rawCodec{}
+
+func (rawCodec) Marshal(v any) (mem.BufferSlice, error) {
+	f, ok := v.(*frame)
+	if !ok {
+		return nil, fmt.Errorf("proxy: cannot marshal %T", v)
+	}
+	return mem.BufferSlice{mem.SliceBuffer(f.data)}, nil
+}
+
+func (rawCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	f, ok := v.(*frame)
+	if !ok {
+		return fmt.Errorf("proxy: cannot unmarshal into %T", v)
+	}
+	f.data = data.Materialize()
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,146 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type traceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+	exports atomic.Int64
+	tenant  atomic.Value
+}
+
+func (s *traceService) Export(ctx context.Context, _ *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	s.exports.Add(1)
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("x-tenant-id")) > 0 {
+		s.tenant.Store(md.Get("x-tenant-id")[0])
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func startProxy(t *testing.T) (*Proxy, *traceService, coltracepb.TraceServiceClient) {
+	t.Helper()
+
+	service := &traceService{}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, service)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener) //nolint:errcheck
+	t.Cleanup(server.Stop)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service.exports.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(upstream.Close)
+
+	p := &Proxy{GRPCTarget: listener.Addr().String(), HTTPTarget: upstream.URL}
+	shutdown, err := p.Start(t.Context())
+	require.NoError(t, err, "the proxy must start")
+	t.Cleanup(func() {
+		if err := shutdown(context.Background()); err != nil {
+			t.Logf("error shutting down the proxy: %v", err)
+		}
+	})
+
+	conn, err := grpc.NewClient(p.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return p, service, coltracepb.NewTraceServiceClient(conn)
+}
+
+func TestProxyGRPC(t *testing.T) {
+	t.Parallel()
+	p, service, client := startProxy(t)
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "x-tenant-id", "tenant-a")
+
+	_, err := client.Export(ctx, &coltracepb.ExportTraceServiceRequest{})
+	require.NoError(t, err, "requests must be forwarded when no fault is injected")
+	assert.Equal(t, int64(1), service.exports.Load())
+	assert.Equal(t, "tenant-a", service.tenant.Load(), "metadata must be forwarded")
+
+	p.Inject(Throttled(time.Second*2).Times(1), Unavailable(0).Times(1))
+
+	_, err = client.Export(ctx, &coltracepb.ExportTraceServiceRequest{})
+	s := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, s.Code())
+	require.Len(t, s.Details(), 1)
+	retryInfo, ok := s.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok, "the retry info must be returned")
+	assert.Equal(t, time.Second*2, retryInfo.GetRetryDelay().AsDuration())
+
+	_, err = client.Export(ctx, &coltracepb.ExportTraceServiceRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = client.Export(ctx, &coltracepb.ExportTraceServiceRequest{})
+	require.NoError(t, err, "requests must be forwarded once the faults are used up")
+
+	p.Inject(Latency(time.Millisecond * 200))
+	start := time.Now()
+	_, err = client.Export(ctx, &coltracepb.ExportTraceServiceRequest{})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*200)
+
+	p.Clear()
+	p.Inject(DropConnection().Times(1))
+	_, err = client.Export(ctx, &coltracepb.ExportTraceServiceRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	assert.Equal(t, Stats{GRPCRequests: 6, Faulted: 3, Forwarded: 3}, p.Stats())
+}
+
+func TestProxyHTTP(t *testing.T) {
+	t.Parallel()
+	p, service, _ := startProxy(t)
+
+	post := func() (*http.Response, error) {
+		return http.Post(p.HTTPEndpoint()+"/v1/traces", "application/x-protobuf", strings.NewReader(""))
+	}
+
+	resp, err := post()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(1), service.exports.Load())
+
+	p.Inject(Throttled(time.Second).Times(1), DropConnection().Times(1))
+
+	resp, err = post()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	_, err = post()
+	require.Error(t, err, "the connection must be dropped")
+
+	resp, err = post()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, Stats{HTTPRequests: 4, Faulted: 2, Forwarded: 2}, p.Stats())
+}
+
+func TestProxyStartInvalid(t *testing.T) {
+	t.Parallel()
+	p := &Proxy{GRPCTarget: "localhost:4317"}
+	_, err := p.Start(t.Context())
+	require.Error(t, err)
+}
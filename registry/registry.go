@@ -0,0 +1,147 @@
+// Package registry holds the configuration used to resolve and pull the images of the stack,
+// such as a registry mirror, per-image overrides, pull policies and registry credentials.
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/containerd/errdefs"
+	"github.com/moby/moby/api/types/registry"
+	"github.com/moby/moby/client"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// PullPolicy decides when an image is pulled before its container is started.
+type PullPolicy int
+
+const (
+	// PullMissing only pulls images that are not already present locally.
+	PullMissing PullPolicy = iota
+	// PullAlways pulls images every time a container is started.
+	PullAlways
+)
+
+// Credentials hold the username and password (or token) used to authenticate against a registry.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Config holds the image configuration that is applied to every container of the stack.
+// A nil Config leaves images untouched and uses testcontainers' defaults.
+type Config struct {
+	// Overrides replaces images by name, e.g. {"prom/prometheus:v3.2.1": "prom/prometheus:v3.3.0"}.
+	Overrides map[string]string
+	// Prefix is prepended to every image, e.g. "mirror.internal/dockerhub".
+	Prefix string
+	// Rewrite is applied to every image after Prefix, and can be used for rules
+	// that a prefix cannot express.
+	Rewrite func(image string) string
+	// PullPolicy decides when images are pulled.
+	PullPolicy PullPolicy
+	// Credentials are used to pull every image when set. Otherwise testcontainers falls back to
+	// DOCKER_AUTH_CONFIG or the docker config file.
+	Credentials *Credentials
+}
+
+// Image resolves the image that should be used in place of the given one. Overrides are
+// looked up first, and Prefix and Rewrite are then applied to the result.
+func (c *Config) Image(image string) string {
+	if c == nil {
+		return image
+	}
+
+	if override, ok := c.Overrides[image]; ok {
+		image = override
+	}
+
+	if c.Prefix != "" {
+		image = strings.TrimSuffix(c.Prefix, "/") + "/" + image
+	}
+
+	if c.Rewrite != nil {
+		image = c.Rewrite(image)
+	}
+
+	return image
+}
+
+// Prepare resolves the image of the container request and applies the pull policy and
+// credentials to it. Images that need credentials are pulled before the container is created.
+func (c *Config) Prepare(ctx context.Context, req *testcontainers.ContainerRequest) error {
+	if c == nil {
+		return nil
+	}
+
+	req.Image = c.Image(req.Image)
+	req.AlwaysPullImage = c.PullPolicy == PullAlways
+
+	if c.Credentials == nil {
+		return nil
+	}
+
+	if err := c.pull(ctx, req.Image); err != nil {
+		return fmt.Errorf("registry: could not pull %s: %w", req.Image, err)
+	}
+	// the image is now present, so testcontainers must not pull it again without the credentials
+	req.AlwaysPullImage = false
+
+	return nil
+}
+
+func (c *Config) pull(ctx context.Context, image string) error {
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return fmt.Errorf("could not create a docker client: %w", err)
+	}
+	defer cli.Close() //nolint:errcheck
+
+	if c.PullPolicy == PullMissing {
+		_, err := cli.ImageInspect(ctx, image)
+		if err == nil {
+			return nil
+		}
+		if !errdefs.IsNotFound(err) {
+			return fmt.Errorf("could not inspect the image: %w", err)
+		}
+	}
+
+	auth, err := encodeAuth(registry.AuthConfig{
+		Username:      c.Credentials.Username,
+		Password:      c.Credentials.Password,
+		ServerAddress: serverAddress(image),
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := cli.ImagePull(ctx, image, client.ImagePullOptions{RegistryAuth: auth})
+	if err != nil {
+		return err
+	}
+	defer resp.Close() //nolint:errcheck
+
+	return resp.Wait(ctx)
+}
+
+func encodeAuth(auth registry.AuthConfig) (string, error) {
+	b, err := json.Marshal(auth)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal the registry credentials: %w", err)
+	}
+	return base64.URLEncoding.EncodeToString(b), nil
+}
+
+// serverAddress returns the registry host of an image, following docker's rule that the first
+// path component is a registry if it contains a '.' or ':', or is localhost.
+func serverAddress(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first
+	}
+	return "docker.io"
+}
@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/moby/moby/api/types/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestImage(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		name   string
+		config *Config
+		image  string
+		want   string
+	}{
+		{"nil config", nil, "prom/prometheus:v3.2.1", "prom/prometheus:v3.2.1"},
+		{"empty config", &Config{}, "prom/prometheus:v3.2.1", "prom/prometheus:v3.2.1"},
+		{"prefix", &Config{Prefix: "mirror.internal/dockerhub/"}, "datalust/seq:2024.3", "mirror.internal/dockerhub/datalust/seq:2024.3"},
+		{"override", &Config{
+			Overrides: map[string]string{"datalust/seq:2024.3": "datalust/seq:2025.1"},
+			Prefix:    "mirror.internal",
+		}, "datalust/seq:2024.3", "mirror.internal/datalust/seq:2025.1"},
+		{"rewrite", &Config{
+			Prefix: "mirror.internal",
+			Rewrite: func(image string) string {
+				return strings.Replace(image, ":latest", ":2.4.0", 1)
+			},
+		}, "jaegertracing/jaeger:latest", "mirror.internal/jaegertracing/jaeger:2.4.0"},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.config.Image(tt.image))
+		})
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	t.Parallel()
+
+	t.Run("always pull", func(t *testing.T) {
+		t.Parallel()
+		c := &Config{Prefix: "mirror.internal", PullPolicy: PullAlways}
+		req := testcontainers.ContainerRequest{Image: "otel/opentelemetry-collector:0.117.0"}
+
+		require.NoError(t, c.Prepare(t.Context(), &req))
+		assert.Equal(t, "mirror.internal/otel/opentelemetry-collector:0.117.0", req.Image)
+		assert.True(t, req.AlwaysPullImage)
+	})
+
+	t.Run("nil config", func(t *testing.T) {
+		t.Parallel()
+		var c *Config
+		req := testcontainers.ContainerRequest{Image: "otel/opentelemetry-collector:0.117.0"}
+
+		require.NoError(t, c.Prepare(t.Context(), &req))
+		assert.Equal(t, "otel/opentelemetry-collector:0.117.0", req.Image)
+		assert.False(t, req.AlwaysPullImage)
+	})
+}
+
+func TestServerAddress(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "docker.io", serverAddress("prom/prometheus:v3.2.1"))
+	assert.Equal(t, "docker.io", serverAddress("alpine"))
+	assert.Equal(t, "mirror.internal", serverAddress("mirror.internal/prom/prometheus:v3.2.1"))
+	assert.Equal(t, "localhost:5000", serverAddress("localhost:5000/prom/prometheus:v3.2.1"))
+	assert.Equal(t, "localhost", serverAddress("localhost/prometheus"))
+}
+
+func TestEncodeAuth(t *testing.T) {
+	t.Parallel()
+	encoded, err := encodeAuth(registry.AuthConfig{Username: "user", Password: "pass", ServerAddress: "mirror.internal"})
+	require.NoError(t, err)
+
+	decoded, err := base64.URLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+
+	var auth registry.AuthConfig
+	require.NoError(t, json.Unmarshal(decoded, &auth))
+	assert.Equal(t, "user", auth.Username)
+	assert.Equal(t, "pass", auth.Password)
+	assert.Equal(t, "mirror.internal", auth.ServerAddress)
+}
@@ -2,6 +2,7 @@
 package request
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -28,10 +29,38 @@ var retryCodes = []int{
 }
 
 // Request sends a GET request to the specified endpoint and unmarshals the response body into the provided struct.
-func Request[U any](endpoint string, unmarshal *U) (err error) {
-	resp, err := http.Get(endpoint)
+func Request[U any](endpoint string, unmarshal *U) error {
+	return RequestContext(context.Background(), endpoint, unmarshal)
+}
+
+// RequestContext is like Request, but sends the request with the given context.
+func RequestContext[U any](ctx context.Context, endpoint string, unmarshal *U) error {
+	body, err := Get(ctx, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(body, unmarshal)
 	if err != nil {
-		return fmt.Errorf("request: could not get response on endpoint %s: %w", endpoint, err)
+		return fmt.Errorf("request: could not unmarshal response body %s: %w", string(body), err)
+	}
+
+	return nil
+}
+
+// Get sends a GET request with the given headers to the specified endpoint and returns the response body.
+func Get(ctx context.Context, endpoint string, header http.Header) (body []byte, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request: could not create request for endpoint %s: %w", endpoint, err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request: could not get response on endpoint %s: %w", endpoint, err)
 	}
 
 	defer func() {
@@ -49,18 +78,13 @@ func Request[U any](endpoint string, unmarshal *U) (err error) {
 			err = ErrNonRetryableCode
 		}
 
-		return fmt.Errorf("request: response from was not 200: got %d on endpoint %s: %w", resp.StatusCode, endpoint, err)
+		return nil, fmt.Errorf("request: response from was not 200: got %d on endpoint %s: %w", resp.StatusCode, endpoint, err)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("request: could not read body from response for endpoint %s: %w", endpoint, err)
+		return nil, fmt.Errorf("request: could not read body from response for endpoint %s: %w", endpoint, err)
 	}
 
-	err = json.Unmarshal(body, unmarshal)
-	if err != nil {
-		return fmt.Errorf("request: could not unmarshal response body %s: %w", string(body), err)
-	}
-
-	return nil
+	return body, nil
 }
@@ -139,4 +139,31 @@ func TestRequest(t *testing.T) {
 		var syntaxError *json.SyntaxError
 		assert.ErrorAs(t, err, &syntaxError)
 	})
+
+	t.Run("get with headers", func(t *testing.T) {
+		s := http.Server{Addr: "localhost:45682"}
+		go func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(r.Header.Get("Accept"))) //nolint:errcheck
+			})
+
+			s.Handler = mux
+			if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				t.Logf("error serving server: %v", err)
+			}
+		}()
+
+		t.Cleanup(func() {
+			if err := s.Shutdown(context.Background()); err != nil {
+				t.Logf("error shutting down server: %v", err)
+			}
+		})
+
+		time.Sleep(time.Millisecond + 200)
+
+		body, err := Get(t.Context(), "http://"+s.Addr+"/", http.Header{"Accept": {"application/protobuf"}})
+		require.NoError(t, err)
+		assert.Equal(t, "application/protobuf", string(body))
+	})
 }
@@ -0,0 +1,63 @@
+package seq
+
+import (
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+)
+
+var _ backend.LogBackend = (*Seq)(nil)
+
+// eventsResponse is like Events, but keeps every resource attribute rather than only the name.
+type eventsResponse []struct {
+	Timestamp  time.Time  `json:"Timestamp"`
+	Properties []Property `json:"Properties"`
+	Messages   []Message  `json:"MessageTemplateTokens"`
+	Exception  string     `json:"Exception"`
+	Level      string     `json:"Level"`
+	TraceID    string     `json:"TraceId"`
+	SpanID     string     `json:"SpanId"`
+	Resource   []Property `json:"Resource"`
+}
+
+// LogRecords implements backend.LogBackend, returning the same events as GetEvents in a backend-neutral form.
+// Seq nests dotted attribute names into objects, which are flattened back into dotted names, and the exception
+// is returned as the exception.stacktrace attribute.
+func (s *Seq) LogRecords(expectedRecords int, maxRetries int) ([]backend.LogRecord, error) {
+	events, _, err := fetchEvents[eventsResponse](s, expectedRecords, maxRetries)
+	return neutralLogRecords(events), err
+}
+
+func neutralLogRecords(events eventsResponse) []backend.LogRecord {
+	records := make([]backend.LogRecord, 0, len(events))
+	for _, e := range events {
+		var body strings.Builder
+		for _, m := range e.Messages {
+			body.WriteString(m.Text)
+		}
+
+		record := backend.LogRecord{
+			Time:       e.Timestamp,
+			Body:       body.String(),
+			Level:      e.Level,
+			TraceID:    e.TraceID,
+			SpanID:     e.SpanID,
+			Attributes: backend.Flatten(propertyValues(e.Properties)),
+			Resource:   backend.Flatten(propertyValues(e.Resource)),
+		}
+		if e.Exception != "" {
+			record.Attributes["exception.stacktrace"] = e.Exception
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func propertyValues(properties []Property) map[string]any {
+	values := make(map[string]any, len(properties))
+	for _, p := range properties {
+		values[p.Name] = p.Value
+	}
+	return values
+}
@@ -0,0 +1,84 @@
+package seq
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRecords(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/events" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`[{
+			"Timestamp":"2024-01-02T03:04:05Z",
+			"Properties":[{"Name":"http","Value":{"request":{"method":"GET"}}},{"Name":"user","Value":"u-1"}],
+			"MessageTemplateTokens":[{"Text":"request "},{"Text":"failed"}],
+			"Exception":"panic: boom",
+			"Level":"Error",
+			"TraceId":"0a",
+			"SpanId":"01",
+			"Resource":[{"Name":"service","Value":{"name":"api","version":"1.0.0"}}]
+		}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	s := Seq{Host: "127.0.0.1", Ports: map[int]nat.Port{80: nat.Port(port)}}
+
+	records, err := s.LogRecords(1, 1)
+	require.NoError(t, err, "must be able to get the log records")
+	assert.Equal(t, []backend.LogRecord{{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Body:    "request failed",
+		Level:   "Error",
+		TraceID: "0a",
+		SpanID:  "01",
+		Attributes: map[string]any{
+			"http.request.method":  "GET",
+			"user":                 "u-1",
+			"exception.stacktrace": "panic: boom",
+		},
+		Resource: map[string]any{"service.name": "api", "service.version": "1.0.0"},
+	}}, records)
+
+	_, err = s.LogRecords(2, 1)
+	require.Error(t, err, "must fail when the expected records are not returned")
+}
+
+func TestLogRecordsRetry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"MessageTemplateTokens":[{"Text":"test message"}]}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	s := Seq{Host: "127.0.0.1", Ports: map[int]nat.Port{80: nat.Port(port)}}
+
+	records, err := s.LogRecords(1, 2)
+	require.NoError(t, err, "a 503 while seq starts must be retried")
+	require.Len(t, records, 1)
+	assert.Equal(t, "test message", records[0].Body)
+}
@@ -47,15 +47,19 @@ type Resource struct {
 	} `json:"Value"`
 }
 
-var errRespCode = fmt.Errorf("the return was not of status 200")
-
 // GetEvents takes returns the last n logging events that were received by Seq.
 // There is a retry mechanism implemented; `GetEvents` will keep fetching every 2 seconds, for a maximum
 // of `maxRetries` times, until Jaeger returns `expectedEvents` number of events.
 func (s *Seq) GetEvents(expectedEvents int, maxRetries int) (Events, string, error) {
-	var events Events
-	endpoint := fmt.Sprintf("http://localhost:%d/api/events?count=%d", s.Ports[80].Int(), expectedEvents)
+	return fetchEvents[Events](s, expectedEvents, maxRetries)
+}
+
+// fetchEvents decodes the events into T, retrying as described on GetEvents. T is the shape of the response that
+// the caller needs, and is always a slice of events.
+func fetchEvents[T ~[]E, E any](s *Seq, expectedEvents int, maxRetries int) (T, string, error) {
+	endpoint := fmt.Sprintf("http://%s:%d/api/events?count=%d", s.host(), s.Ports[80].Int(), expectedEvents)
 
+	var events T
 	var attempts int
 	for {
 		attempts++
@@ -63,8 +67,9 @@ func (s *Seq) GetEvents(expectedEvents int, maxRetries int) (Events, string, err
 			time.Sleep(time.Second * 2)
 		}
 
+		events = nil
 		err := request.Request(endpoint, &events)
-		if err != nil && !errors.Is(err, errRespCode) {
+		if err != nil && !errors.Is(err, request.ErrRetryableCode) {
 			return events, endpoint, fmt.Errorf("seq: request returned a non-retryable error: %w", err)
 		}
 
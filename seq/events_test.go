@@ -36,7 +36,7 @@ func TestGetEvents(t *testing.T) {
 	})
 
 	c := collector.Collector{Network: s.Network}
-	collectorShutdownFunc, err := c.Start(t.Context(), "jaeger", s.Name)
+	collectorShutdownFunc, err := c.Start(t.Context(), "jaeger", s.Alias)
 	require.NoError(t, err, "seq must be able to start")
 	t.Cleanup(func() {
 		if err := collectorShutdownFunc(context.Background()); err != nil {
@@ -4,20 +4,35 @@ package seq
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/adreasnow/otelstack/registry"
 	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+// DefaultImage is the image used for the Seq container.
+const DefaultImage = "datalust/seq:2024.3"
+
+// DefaultAlias is the network alias that other containers use to reach Seq.
+const DefaultAlias = "seq"
+
 // Seq hold the testcontainer, ports and network used by Seq. If instantiating yourself,
 // be sure to populate Seq.Network, otherwise a new network will be generated.
+// Host is the hostname that the ports are reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
 type Seq struct {
-	Ports   map[int]nat.Port
-	Network *testcontainers.DockerNetwork
-	Name    string
+	Ports    map[int]nat.Port
+	Network  *testcontainers.DockerNetwork
+	Host     string
+	Image    string
+	Registry *registry.Config
+	Alias    string
+	Name     string
 }
 
 // Start starts the Seq container.
@@ -34,15 +49,25 @@ func (s *Seq) Start(ctx context.Context) (func(context.Context) error, error) {
 		}
 	}
 
+	if s.Alias == "" {
+		s.Alias = DefaultAlias
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          s.image(),
+		ExposedPorts:   []string{"80/tcp", "5341/tcp"},
+		Networks:       []string{s.Network.Name},
+		NetworkAliases: map[string][]string{s.Network.Name: {s.Alias}},
+		WaitingFor:     wait.ForLog("Seq listening on"),
+		Env:            map[string]string{"ACCEPT_EULA": "Y"},
+	}
+	if err := s.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("seq: could not prepare the image: %w", err)
+	}
+
 	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: testcontainers.ContainerRequest{
-			Image:        "datalust/seq:2024.3",
-			ExposedPorts: []string{"80/tcp", "5341/tcp"},
-			Networks:     []string{s.Network.Name},
-			WaitingFor:   wait.ForLog("Seq listening on"),
-			Env:          map[string]string{"ACCEPT_EULA": "Y"},
-		},
-		Started: true,
+		ContainerRequest: req,
+		Started:          true,
 	})
 	if err != nil {
 		return emptyFunc, fmt.Errorf("seq: could not start the testcontainer: %w", err)
@@ -52,7 +77,7 @@ func (s *Seq) Start(ctx context.Context) (func(context.Context) error, error) {
 	if err != nil {
 		return emptyFunc, fmt.Errorf("seq: could not read the name of the container from the testcontainer: %w", err)
 	}
-	s.Name = s.Name[1:]
+	s.Name = strings.TrimPrefix(s.Name, "/")
 
 	for _, portNum := range []int{80, 5341} {
 		s.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
@@ -65,3 +90,22 @@ func (s *Seq) Start(ctx context.Context) (func(context.Context) error, error) {
 		return container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
 	}, nil
 }
+
+// Endpoint returns the URL that the given container port of Seq can be reached on.
+func (s *Seq) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", s.host(), s.Ports[port].Int())
+}
+
+func (s *Seq) host() string {
+	if s.Host == "" {
+		return "localhost"
+	}
+	return s.Host
+}
+
+func (s *Seq) image() string {
+	if s.Image == "" {
+		return DefaultImage
+	}
+	return s.Image
+}
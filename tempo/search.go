@@ -0,0 +1,102 @@
+package tempo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/adreasnow/otelstack/request"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ backend.TraceBackend = (*Tempo)(nil)
+
+// searchLimit is the most traces that Search returns.
+const searchLimit = 100
+
+type searchResponse struct {
+	Traces []struct {
+		TraceID         string `json:"traceID"`
+		RootServiceName string `json:"rootServiceName"`
+		RootTraceName   string `json:"rootTraceName"`
+	} `json:"traces"`
+}
+
+// Search runs a TraceQL query (e.g. `{ resource.service.name = "api" && status = error }`) over the traces received
+// in the last window, and returns the matching traces, most recent first, with all of their spans.
+func (t *Tempo) Search(ctx context.Context, traceql string, window time.Duration) ([]backend.Trace, error) {
+	// the end is rounded up, as the API takes whole seconds
+	end := time.Now().Add(time.Second)
+	endpoint := fmt.Sprintf("%s/api/search?q=%s&start=%d&end=%d&limit=%d",
+		t.Endpoint(3200), url.QueryEscape(traceql), end.Add(-window).Unix(), end.Unix(), searchLimit)
+
+	var u searchResponse
+	if err := request.RequestContext(ctx, endpoint, &u); err != nil {
+		return nil, fmt.Errorf("tempo: could not search for traces: %w", err)
+	}
+
+	traces := make([]backend.Trace, 0, len(u.Traces))
+	for _, result := range u.Traces {
+		trace, err := t.GetTrace(ctx, result.TraceID)
+		if err != nil {
+			return traces, err
+		}
+		traces = append(traces, trace)
+	}
+	return traces, nil
+}
+
+// GetTrace returns the trace with the given hex encoded ID.
+func (t *Tempo) GetTrace(ctx context.Context, id string) (backend.Trace, error) {
+	endpoint := fmt.Sprintf("%s/api/traces/%s", t.Endpoint(3200), url.PathEscape(id))
+
+	// requested as protobuf, which is wire compatible with OTLP's TracesData, rather than as JSON with base64 IDs
+	body, err := request.Get(ctx, endpoint, http.Header{"Accept": {"application/protobuf"}})
+	if err != nil {
+		return backend.Trace{}, fmt.Errorf("tempo: could not get trace %s: %w", id, err)
+	}
+
+	var data tracepb.TracesData
+	if err := proto.Unmarshal(body, &data); err != nil {
+		return backend.Trace{}, fmt.Errorf("tempo: could not decode trace %s: %w", id, err)
+	}
+
+	traces := backend.TracesFromOTLP(data.GetResourceSpans())
+	if len(traces) == 0 {
+		return backend.Trace{}, fmt.Errorf("tempo: trace %s has no spans", id)
+	}
+	return traces[0], nil
+}
+
+// Traces implements backend.TraceBackend by searching for the traces of the service received in the last hour.
+// There is a retry mechanism implemented; `Traces` will keep searching every 2 seconds, for a maximum
+// of `maxRetries` times, until Tempo returns `expectedTraces` number of traces.
+func (t *Tempo) Traces(expectedTraces int, maxRetries int, service string) ([]backend.Trace, error) {
+	traceql := fmt.Sprintf("{ resource.service.name = %q }", service)
+
+	var attempts int
+	for {
+		attempts++
+		if attempts > 1 {
+			time.Sleep(time.Second * 2)
+		}
+
+		traces, err := t.Search(context.Background(), traceql, time.Hour)
+		if err != nil && !errors.Is(err, request.ErrRetryableCode) {
+			return traces, err
+		}
+
+		if len(traces) >= expectedTraces {
+			return traces, nil
+		}
+
+		if attempts >= maxRetries {
+			return traces, fmt.Errorf("tempo: could not get %d traces in %d attempts", expectedTraces, maxRetries)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package tempo
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func testServer(t *testing.T) Tempo {
+	t.Helper()
+
+	start := uint64(time.UnixMicro(1700000000000000).UnixNano())
+	trace, err := proto.Marshal(&tracepb.TracesData{ResourceSpans: []*tracepb.ResourceSpans{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{
+			Key:   "service.name",
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "api"}},
+		}}},
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+			TraceId:           []byte{0x0a, 0x0b},
+			SpanId:            []byte{0x01},
+			Name:              "GET /users",
+			Kind:              tracepb.Span_SPAN_KIND_SERVER,
+			StartTimeUnixNano: start,
+			EndTimeUnixNano:   start + uint64(time.Millisecond*2),
+			Status:            &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: "boom"},
+		}}}},
+	}}})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/search":
+			if r.URL.Query().Get("q") != `{ resource.service.name = "api" }` || r.URL.Query().Get("start") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"traces":[{"traceID":"0a0b","rootServiceName":"api","rootTraceName":"GET /users"}]}`))
+		case "/api/traces/0a0b":
+			if r.Header.Get("Accept") != "application/protobuf" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			_, _ = w.Write(trace)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	return Tempo{Host: "127.0.0.1", Ports: map[int]nat.Port{3200: nat.Port(port)}}
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+	tp := testServer(t)
+
+	traces, err := tp.Search(t.Context(), `{ resource.service.name = "api" }`, time.Hour)
+	require.NoError(t, err, "must be able to search for traces")
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0].Spans, 1)
+	assert.Equal(t, backend.Span{
+		TraceID:       "0a0b",
+		SpanID:        "01",
+		Name:          "GET /users",
+		Service:       "api",
+		Kind:          "server",
+		StartTime:     time.UnixMicro(1700000000000000),
+		Duration:      time.Millisecond * 2,
+		Status:        "ERROR",
+		StatusMessage: "boom",
+		Attributes:    map[string]any{},
+		Resource:      map[string]any{"service.name": "api"},
+	}, traces[0].Spans[0])
+}
+
+func TestGetTrace(t *testing.T) {
+	t.Parallel()
+	tp := testServer(t)
+
+	trace, err := tp.GetTrace(t.Context(), "0a0b")
+	require.NoError(t, err, "must be able to get the trace")
+	assert.Equal(t, "0a0b", trace.TraceID)
+
+	_, err = tp.GetTrace(t.Context(), "ffff")
+	require.Error(t, err, "must fail when the trace does not exist")
+}
+
+func TestTraces(t *testing.T) {
+	t.Parallel()
+	tp := testServer(t)
+
+	traces, err := tp.Traces(1, 1, "api")
+	require.NoError(t, err, "must be able to get the traces")
+	assert.Len(t, traces, 1)
+
+	_, err = tp.Traces(2, 1, "api")
+	require.Error(t, err, "must fail when the expected traces are not returned")
+}
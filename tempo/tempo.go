@@ -0,0 +1,150 @@
+// Package tempo holds the resources needed to start a Grafana Tempo testcontainer, and to query it with TraceQL.
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/registry"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// DefaultImage is the image used for the Tempo container.
+const DefaultImage = "grafana/tempo:2.7.1"
+
+// DefaultAlias is the network alias that other containers use to reach Tempo.
+const DefaultAlias = "tempo"
+
+// Tempo hold the testcontainer, ports and network used by Tempo. If instantiating yourself,
+// be sure to populate Tempo.Network, otherwise a new network will be generated.
+// The HTTP API (and TraceQL search) is on port 3200, and OTLP is received on ports 4317 and 4318.
+// Host is the hostname that the ports are reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
+type Tempo struct {
+	Ports    map[int]nat.Port
+	Network  *testcontainers.DockerNetwork
+	Host     string
+	Image    string
+	Registry *registry.Config
+	Alias    string
+	Name     string
+}
+
+// Start starts the Tempo container.
+func (t *Tempo) Start(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+	var err error
+
+	t.Ports = make(map[int]nat.Port)
+
+	if t.Network == nil {
+		t.Network, err = network.New(ctx)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("tempo: network not provided and could not create a new one: %w", err)
+		}
+	}
+
+	if t.Alias == "" {
+		t.Alias = DefaultAlias
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          t.image(),
+		ExposedPorts:   []string{"3200/tcp", "4317/tcp", "4318/tcp"},
+		Networks:       []string{t.Network.Name},
+		NetworkAliases: map[string][]string{t.Network.Name: {t.Alias}},
+		// the ingester only reports ready after it has waited for the ring to settle
+		WaitingFor: wait.ForHTTP("/ready").WithPort("3200/tcp").WithStartupTimeout(time.Minute * 2),
+		Cmd:        []string{"-config.file=/etc/tempo/config.yaml"},
+		Files: []testcontainers.ContainerFile{{
+			ContainerFilePath: "/etc/tempo/config.yaml",
+			Reader:            strings.NewReader(config),
+			FileMode:          0644,
+		}},
+	}
+	if err := t.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("tempo: could not prepare the image: %w", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return emptyFunc, fmt.Errorf("tempo: could not start the testcontainer: %w", err)
+	}
+
+	t.Name, err = container.Name(ctx)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("tempo: could not read the name of the container from the testcontainer: %w", err)
+	}
+	t.Name = strings.TrimPrefix(t.Name, "/")
+
+	for _, portNum := range []int{3200, 4317, 4318} {
+		t.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
+		if err != nil {
+			return emptyFunc, fmt.Errorf("tempo: could not retrieve port %d from the testcontainer: %w", portNum, err)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
+	}, nil
+}
+
+// Config returns the Tempo configuration used by the container.
+func (t *Tempo) Config() string {
+	return config
+}
+
+var config = `stream_over_http_enabled: true
+
+server:
+  http_listen_port: 3200
+  log_level: warn
+
+distributor:
+  receivers:
+    otlp:
+      protocols:
+        grpc:
+          endpoint: "0.0.0.0:4317"
+        http:
+          endpoint: "0.0.0.0:4318"
+
+ingester:
+  max_block_duration: 1m
+
+storage:
+  trace:
+    backend: local
+    wal:
+      path: /tmp/tempo/wal
+    local:
+      path: /tmp/tempo/blocks
+`
+
+// Endpoint returns the URL that the given container port of Tempo can be reached on.
+func (t *Tempo) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", t.host(), t.Ports[port].Int())
+}
+
+func (t *Tempo) host() string {
+	if t.Host == "" {
+		return "localhost"
+	}
+	return t.Host
+}
+
+func (t *Tempo) image() string {
+	if t.Image == "" {
+		return DefaultImage
+	}
+	return t.Image
+}
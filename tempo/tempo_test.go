@@ -0,0 +1,30 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTempoStart(t *testing.T) {
+	t.Parallel()
+	tp := Tempo{}
+	shutdownFunc, err := tp.Start(t.Context())
+	require.NoError(t, err, "tempo must be able to start")
+	t.Cleanup(func() {
+		if err := shutdownFunc(context.Background()); err != nil {
+			t.Logf("error shutting down tempo: %v", err)
+		}
+	})
+
+	endpoint := fmt.Sprintf("http://localhost:%d/ready", tp.Ports[3200].Int())
+	t.Logf("using endpoint: %s", endpoint)
+
+	resp, err := http.Get(endpoint)
+	require.NoError(t, err, "must be able to call tempo")
+	assert.Equal(t, 200, resp.StatusCode, "request should be 200")
+}
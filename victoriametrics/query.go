@@ -0,0 +1,65 @@
+package victoriametrics
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/adreasnow/otelstack/prometheus"
+	"github.com/adreasnow/otelstack/request"
+	"github.com/docker/go-connections/nat"
+)
+
+var _ backend.MetricBackend = (*VictoriaMetrics)(nil)
+
+type queryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string               `json:"resultType"`
+		Result     []prometheus.Metrics `json:"result"`
+	} `json:"data"`
+}
+
+// QueryRange runs a MetricsQL (or PromQL) range query between start and end, evaluated every step, and returns
+// the resulting series in the same form as the prometheus package. The step must be at least a millisecond.
+func (v *VictoriaMetrics) QueryRange(ctx context.Context, metricsql string, start time.Time, end time.Time, step time.Duration) ([]prometheus.Metrics, error) {
+	if step < time.Millisecond {
+		return nil, fmt.Errorf("victoriametrics: the step %s is shorter than a millisecond", step)
+	}
+	endpoint := fmt.Sprintf("%s/api/v1/query_range?query=%s&start=%d&end=%d&step=%dms",
+		v.Endpoint(8428), url.QueryEscape(metricsql), start.Unix(), end.Unix(), step.Milliseconds())
+
+	var u queryResponse
+	if err := request.RequestContext(ctx, endpoint, &u); err != nil {
+		return nil, fmt.Errorf("victoriametrics: could not query metrics: %w", err)
+	}
+	if u.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("victoriametrics: query returned %q rather than a matrix", u.Data.ResultType)
+	}
+	return u.Data.Result, nil
+}
+
+// GetMetrics is like prometheus.Prometheus.GetMetrics, and queries VictoriaMetrics' Prometheus compatible API.
+func (v *VictoriaMetrics) GetMetrics(expectedDataPoints int, maxRetries int, metricName string, service string, since time.Duration) (prometheus.Metrics, string, error) {
+	metrics, endpoint, err := v.prometheus().GetMetrics(expectedDataPoints, maxRetries, metricName, service, since)
+	if err != nil {
+		return metrics, endpoint, fmt.Errorf("victoriametrics: %w", err)
+	}
+	return metrics, endpoint, nil
+}
+
+// Series implements backend.MetricBackend, returning the same series as GetMetrics in a backend-neutral form.
+func (v *VictoriaMetrics) Series(expectedPoints int, maxRetries int, metricName string, service string, since time.Duration) (backend.Series, error) {
+	series, err := v.prometheus().Series(expectedPoints, maxRetries, metricName, service, since)
+	if err != nil {
+		return series, fmt.Errorf("victoriametrics: %w", err)
+	}
+	return series, nil
+}
+
+// prometheus returns a Prometheus client that points at VictoriaMetrics' Prometheus compatible API.
+func (v *VictoriaMetrics) prometheus() *prometheus.Prometheus {
+	return &prometheus.Prometheus{Host: v.host(), Ports: map[int]nat.Port{9090: v.Ports[8428]}}
+}
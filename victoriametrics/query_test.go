@@ -0,0 +1,78 @@
+package victoriametrics
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adreasnow/otelstack/prometheus"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(t *testing.T) VictoriaMetrics {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if step, err := time.ParseDuration(r.URL.Query().Get("step")); r.URL.Path != "/api/v1/query_range" || err != nil || step <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("query") {
+		case `goroutine_count{service_name="api"}`, `rollup_rate(goroutine_count{service_name="api"})`:
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"__name__":"goroutine_count","service_name":"api"},"values":[[1700000000,"7"],[1700000010,"8"]]}
+			]}}`))
+		default:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	return VictoriaMetrics{Host: "127.0.0.1", Ports: map[int]nat.Port{8428: nat.Port(port)}}
+}
+
+func TestQueryRange(t *testing.T) {
+	t.Parallel()
+	v := testServer(t)
+
+	end := time.Now()
+	metrics, err := v.QueryRange(t.Context(), `rollup_rate(goroutine_count{service_name="api"})`, end.Add(-time.Minute), end, time.Second*10)
+	require.NoError(t, err, "must be able to run a metricsql query")
+	assert.Equal(t, []prometheus.Metrics{{
+		Metric: map[string]string{"__name__": "goroutine_count", "service_name": "api"},
+		Values: [][]any{{1700000000.0, "7"}, {1700000010.0, "8"}},
+	}}, metrics)
+
+	_, err = v.QueryRange(t.Context(), `rollup_rate(goroutine_count{service_name="api"})`, end.Add(-time.Minute), end, time.Millisecond*500)
+	require.NoError(t, err, "a step under a second must not be truncated to zero")
+
+	_, err = v.QueryRange(t.Context(), `rollup_rate(goroutine_count{service_name="api"})`, end.Add(-time.Minute), end, time.Microsecond)
+	require.Error(t, err, "must fail on a step under a millisecond")
+
+	_, err = v.QueryRange(t.Context(), `invalid(`, end.Add(-time.Minute), end, time.Second*10)
+	require.Error(t, err, "must fail on an invalid query")
+}
+
+func TestGetMetrics(t *testing.T) {
+	t.Parallel()
+	v := testServer(t)
+
+	metrics, _, err := v.GetMetrics(2, 1, "goroutine_count", "api", time.Minute)
+	require.NoError(t, err, "must be able to get the metrics")
+	assert.Len(t, metrics.Values, 2)
+
+	series, err := v.Series(2, 1, "goroutine_count", "api", time.Minute)
+	require.NoError(t, err, "must be able to get the series")
+	assert.Equal(t, "goroutine_count", series.Name)
+	assert.InDelta(t, 8.0, series.Points[1].Value, 0)
+
+	_, _, err = v.GetMetrics(3, 1, "goroutine_count", "api", time.Minute)
+	require.Error(t, err, "must fail when the expected points are not returned")
+}
@@ -0,0 +1,168 @@
+// Package victoriametrics holds the resources needed to start a single-node VictoriaMetrics testcontainer, and to
+// query it with MetricsQL through its Prometheus compatible API.
+package victoriametrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/registry"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// DefaultImage is the image used for the VictoriaMetrics container.
+const DefaultImage = "victoriametrics/victoria-metrics:v1.110.0"
+
+// DefaultAlias is the network alias that other containers use to reach VictoriaMetrics.
+const DefaultAlias = "victoriametrics"
+
+// VictoriaMetrics holds the testcontainer, ports and network used by VictoriaMetrics. If instantiating yourself,
+// be sure to populate VictoriaMetrics.Network, otherwise a new network will be generated.
+// The HTTP API (queries, scrape and OTLP ingestion under /opentelemetry) is on port 8428.
+// Host is the hostname that the port is reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
+// OTLP makes VictoriaMetrics receive metrics over OTLP (see OTLPEndpoint) rather than scrape the collector's
+// prometheus exporter. Either way, the metrics are named like Prometheus names them.
+type VictoriaMetrics struct {
+	Ports    map[int]nat.Port
+	Network  *testcontainers.DockerNetwork
+	Host     string
+	Image    string
+	Registry *registry.Config
+	Alias    string
+	Name     string
+	OTLP     bool
+	config   string
+}
+
+// Start starts the VictoriaMetrics container, scraping the collector through the given network alias unless OTLP is set.
+func (v *VictoriaMetrics) Start(ctx context.Context, collectorAlias string) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+	var err error
+
+	v.Ports = make(map[int]nat.Port)
+
+	if v.Network == nil {
+		v.Network, err = network.New(ctx)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("victoriametrics: network not provided and could not create a new one: %w", err)
+		}
+	}
+
+	v.generateConfig(collectorAlias)
+
+	if v.Alias == "" {
+		v.Alias = DefaultAlias
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          v.image(),
+		ExposedPorts:   []string{"8428/tcp"},
+		Networks:       []string{v.Network.Name},
+		NetworkAliases: map[string][]string{v.Network.Name: {v.Alias}},
+		WaitingFor:     wait.ForHTTP("/health").WithPort("8428/tcp").WithStartupTimeout(time.Minute),
+		Cmd:            v.Command(),
+		Files: []testcontainers.ContainerFile{{
+			ContainerFilePath: "/etc/victoriametrics/scrape.yml",
+			Reader:            strings.NewReader(v.config),
+			FileMode:          0644,
+		}},
+	}
+	if err := v.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("victoriametrics: could not prepare the image: %w", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return emptyFunc, fmt.Errorf("victoriametrics: could not start the testcontainer: %w", err)
+	}
+
+	v.Name, err = container.Name(ctx)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("victoriametrics: could not read the name of the container from the testcontainer: %w", err)
+	}
+	v.Name = strings.TrimPrefix(v.Name, "/")
+
+	for _, portNum := range []int{8428} {
+		v.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
+		if err != nil {
+			return emptyFunc, fmt.Errorf("victoriametrics: could not retrieve port %d from the testcontainer: %w", portNum, err)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
+	}, nil
+}
+
+// Command returns the command that the container is started with.
+func (v *VictoriaMetrics) Command() []string {
+	cmd := []string{
+		"-retentionPeriod=1d",
+		// by default the most recent 30s are hidden from queries, and results are cached
+		"-search.latencyOffset=0s",
+		"-search.disableCache",
+		"-opentelemetry.usePrometheusNaming",
+	}
+	if !v.OTLP {
+		cmd = append(cmd, "-promscrape.config=/etc/victoriametrics/scrape.yml")
+	}
+	return cmd
+}
+
+// Config returns the scrape configuration that is generated for the given collector alias, which is
+// not used when OTLP is set.
+func (v *VictoriaMetrics) Config(collectorAlias string) string {
+	v.generateConfig(collectorAlias)
+	return v.config
+}
+
+func (v *VictoriaMetrics) generateConfig(collectorAlias string) {
+	v.config = fmt.Sprintf(`
+global:
+  scrape_interval: 2s
+
+scrape_configs:
+  - job_name: otel
+    static_configs:
+      - targets: ["%s:8889"]
+`, collectorAlias)
+}
+
+// OTLPEndpoint returns the URL that the collector's otlphttp exporter pushes metrics to on the stack network,
+// to which the exporter appends /v1/metrics.
+func (v *VictoriaMetrics) OTLPEndpoint() string {
+	alias := v.Alias
+	if alias == "" {
+		alias = DefaultAlias
+	}
+	return fmt.Sprintf("http://%s:8428/opentelemetry", alias)
+}
+
+// Endpoint returns the URL that the given container port of VictoriaMetrics can be reached on.
+func (v *VictoriaMetrics) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", v.host(), v.Ports[port].Int())
+}
+
+func (v *VictoriaMetrics) host() string {
+	if v.Host == "" {
+		return "localhost"
+	}
+	return v.Host
+}
+
+func (v *VictoriaMetrics) image() string {
+	if v.Image == "" {
+		return DefaultImage
+	}
+	return v.Image
+}
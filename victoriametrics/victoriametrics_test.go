@@ -0,0 +1,43 @@
+package victoriametrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateConfig(t *testing.T) {
+	t.Parallel()
+	v := VictoriaMetrics{}
+	v.generateConfig("collector")
+
+	assert.Contains(t, v.config, `- targets: ["collector:8889"]`)
+	assert.Contains(t, v.Command(), "-promscrape.config=/etc/victoriametrics/scrape.yml")
+
+	v.OTLP = true
+	assert.NotContains(t, v.Command(), "-promscrape.config=/etc/victoriametrics/scrape.yml", "the collector must not be scraped")
+	assert.Equal(t, "http://victoriametrics:8428/opentelemetry", v.OTLPEndpoint())
+}
+
+func TestVictoriaMetricsStart(t *testing.T) {
+	t.Parallel()
+	v := VictoriaMetrics{}
+	shutdownFunc, err := v.Start(t.Context(), "test")
+	require.NoError(t, err, "victoriametrics must be able to start")
+	t.Cleanup(func() {
+		if err := shutdownFunc(context.Background()); err != nil {
+			t.Logf("error shutting down victoriametrics: %v", err)
+		}
+	})
+
+	endpoint := fmt.Sprintf("http://localhost:%d/health", v.Ports[8428].Int())
+	t.Logf("using endpoint: %s", endpoint)
+
+	resp, err := http.Get(endpoint)
+	require.NoError(t, err, "must be able to call victoriametrics")
+	assert.Equal(t, 200, resp.StatusCode, "request should be 200")
+}
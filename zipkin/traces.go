@@ -0,0 +1,151 @@
+package zipkin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/adreasnow/otelstack/request"
+)
+
+var _ backend.TraceBackend = (*Zipkin)(nil)
+
+// tracesLimit is the most traces that GetTraces returns.
+const tracesLimit = 100
+
+// Span is a span in the Zipkin v2 JSON model. Timestamp and Duration are in microseconds, and Kind is one of
+// CLIENT, SERVER, PRODUCER or CONSUMER when set.
+type Span struct {
+	TraceID        string            `json:"traceId"`
+	ID             string            `json:"id"`
+	ParentID       string            `json:"parentId"`
+	Name           string            `json:"name"`
+	Kind           string            `json:"kind"`
+	Timestamp      int64             `json:"timestamp"`
+	Duration       int64             `json:"duration"`
+	LocalEndpoint  Endpoint          `json:"localEndpoint"`
+	RemoteEndpoint Endpoint          `json:"remoteEndpoint"`
+	Annotations    []Annotation      `json:"annotations"`
+	Tags           map[string]string `json:"tags"`
+	Shared         bool              `json:"shared"`
+}
+
+// Endpoint is the network context of a span.
+type Endpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int    `json:"port"`
+}
+
+// Annotation is a timestamped event of a span, with its Timestamp in microseconds.
+type Annotation struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+// GetTraces returns the traces of the service that started in the last lookback, most recent first.
+func (z *Zipkin) GetTraces(ctx context.Context, service string, lookback time.Duration) ([][]Span, error) {
+	endpoint := fmt.Sprintf("%s/api/v2/traces?serviceName=%s&endTs=%d&lookback=%d&limit=%d",
+		z.Endpoint(9411), url.QueryEscape(service), time.Now().UnixMilli(), lookback.Milliseconds(), tracesLimit)
+
+	var traces [][]Span
+	if err := request.RequestContext(ctx, endpoint, &traces); err != nil {
+		return nil, fmt.Errorf("zipkin: could not get traces: %w", err)
+	}
+	return traces, nil
+}
+
+// GetTrace returns the spans of the trace with the given hex encoded ID.
+func (z *Zipkin) GetTrace(ctx context.Context, id string) ([]Span, error) {
+	endpoint := fmt.Sprintf("%s/api/v2/trace/%s", z.Endpoint(9411), url.PathEscape(id))
+
+	var spans []Span
+	if err := request.RequestContext(ctx, endpoint, &spans); err != nil {
+		return nil, fmt.Errorf("zipkin: could not get trace %s: %w", id, err)
+	}
+	return spans, nil
+}
+
+// Traces implements backend.TraceBackend, returning the traces of the service received in the last hour.
+// Zipkin keeps the span status in the otel.status_code and error tags, and the scope in the otel.scope.name tag,
+// which are moved into the Status, StatusMessage and Scope fields. The resource only holds the service name.
+func (z *Zipkin) Traces(expectedTraces int, maxRetries int, service string) ([]backend.Trace, error) {
+	var attempts int
+	for {
+		attempts++
+		if attempts > 1 {
+			time.Sleep(time.Second * 2)
+		}
+
+		traces, err := z.GetTraces(context.Background(), service, time.Hour)
+		if err != nil && !errors.Is(err, request.ErrRetryableCode) {
+			return nil, err
+		}
+
+		if len(traces) >= expectedTraces {
+			return neutralTraces(traces), nil
+		}
+
+		if attempts >= maxRetries {
+			return neutralTraces(traces), fmt.Errorf("zipkin: could not get %d traces in %d attempts", expectedTraces, maxRetries)
+		}
+	}
+}
+
+func neutralTraces(traces [][]Span) []backend.Trace {
+	neutral := make([]backend.Trace, 0, len(traces))
+	for _, spans := range traces {
+		var trace backend.Trace
+		for _, s := range spans {
+			trace.TraceID = s.TraceID
+			trace.Spans = append(trace.Spans, neutralSpan(s))
+		}
+		neutral = append(neutral, trace)
+	}
+	return neutral
+}
+
+func neutralSpan(s Span) backend.Span {
+	span := backend.Span{
+		TraceID:      s.TraceID,
+		SpanID:       s.ID,
+		ParentSpanID: s.ParentID,
+		Name:         s.Name,
+		Service:      s.LocalEndpoint.ServiceName,
+		Kind:         strings.ToLower(s.Kind),
+		StartTime:    time.UnixMicro(s.Timestamp),
+		Duration:     time.Duration(s.Duration) * time.Microsecond,
+		Attributes:   map[string]any{},
+		Resource:     map[string]any{"service.name": s.LocalEndpoint.ServiceName},
+	}
+
+	for k, v := range s.Tags {
+		switch k {
+		case "otel.status_code":
+			span.Status = v
+		case "error":
+			// the zipkin exporter sets the tag to the status description, which may be empty
+			span.Status = "ERROR"
+			span.StatusMessage = v
+		case "otel.scope.name", "otel.library.name":
+			span.Scope = v
+		case "otel.scope.version", "otel.library.version":
+		default:
+			span.Attributes[k] = v
+		}
+	}
+
+	for _, a := range s.Annotations {
+		span.Events = append(span.Events, backend.Event{
+			Name:       a.Value,
+			Time:       time.UnixMicro(a.Timestamp),
+			Attributes: map[string]any{},
+		})
+	}
+	return span
+}
@@ -0,0 +1,106 @@
+package zipkin
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adreasnow/otelstack/backend"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpans = `[
+	{"traceId":"0a","id":"02","parentId":"01","name":"select","kind":"CLIENT","timestamp":1700000000000000,"duration":1500,
+	 "localEndpoint":{"serviceName":"api"},"remoteEndpoint":{"serviceName":"db","port":5432},
+	 "annotations":[{"timestamp":1700000000000500,"value":"retry"}],
+	 "tags":{"otel.status_code":"ERROR","error":"timeout","otel.scope.name":"sql","db.rows":"3"}},
+	{"traceId":"0a","id":"01","name":"get /users","kind":"SERVER","timestamp":1700000000000000,"duration":2000,
+	 "localEndpoint":{"serviceName":"api"}}
+]`
+
+func testServer(t *testing.T) Zipkin {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/traces":
+			if r.URL.Query().Get("serviceName") != "api" || r.URL.Query().Get("lookback") != "3600000" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`[` + testSpans + `]`))
+		case "/api/v2/trace/0a":
+			_, _ = w.Write([]byte(testSpans))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	return Zipkin{Host: "127.0.0.1", Ports: map[int]nat.Port{9411: nat.Port(port)}}
+}
+
+func TestGetTraces(t *testing.T) {
+	t.Parallel()
+	z := testServer(t)
+
+	traces, err := z.GetTraces(t.Context(), "api", time.Hour)
+	require.NoError(t, err, "must be able to get the traces")
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0], 2)
+	assert.Equal(t, "db", traces[0][0].RemoteEndpoint.ServiceName)
+	assert.Equal(t, 5432, traces[0][0].RemoteEndpoint.Port)
+}
+
+func TestGetTrace(t *testing.T) {
+	t.Parallel()
+	z := testServer(t)
+
+	spans, err := z.GetTrace(t.Context(), "0a")
+	require.NoError(t, err, "must be able to get the trace")
+	assert.Len(t, spans, 2)
+
+	_, err = z.GetTrace(t.Context(), "ff")
+	require.Error(t, err, "must fail when the trace does not exist")
+}
+
+func TestTraces(t *testing.T) {
+	t.Parallel()
+	z := testServer(t)
+
+	traces, err := z.Traces(1, 1, "api")
+	require.NoError(t, err, "must be able to get the traces")
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0].Spans, 2)
+	assert.Equal(t, backend.Span{
+		TraceID:       "0a",
+		SpanID:        "02",
+		ParentSpanID:  "01",
+		Name:          "select",
+		Service:       "api",
+		Kind:          "client",
+		Scope:         "sql",
+		StartTime:     time.UnixMicro(1700000000000000),
+		Duration:      time.Microsecond * 1500,
+		Status:        "ERROR",
+		StatusMessage: "timeout",
+		Attributes:    map[string]any{"db.rows": "3"},
+		Resource:      map[string]any{"service.name": "api"},
+		Events: []backend.Event{{
+			Name:       "retry",
+			Time:       time.UnixMicro(1700000000000500),
+			Attributes: map[string]any{},
+		}},
+	}, traces[0].Spans[0])
+	assert.Equal(t, "server", traces[0].Spans[1].Kind)
+
+	_, err = z.Traces(2, 1, "api")
+	require.Error(t, err, "must fail when the expected traces are not returned")
+}
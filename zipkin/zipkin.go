@@ -0,0 +1,116 @@
+// Package zipkin holds the resources needed to start a Zipkin testcontainer, and to query the traces it has received.
+package zipkin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adreasnow/otelstack/registry"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// DefaultImage is the image used for the Zipkin container.
+const DefaultImage = "openzipkin/zipkin:3.4"
+
+// DefaultAlias is the network alias that other containers use to reach Zipkin.
+const DefaultAlias = "zipkin"
+
+// Zipkin hold the testcontainer, ports and network used by Zipkin. If instantiating yourself,
+// be sure to populate Zipkin.Network, otherwise a new network will be generated.
+// The API and UI, which also receives spans at /api/v2/spans, are on port 9411.
+// Host is the hostname that the port is reachable on and defaults to localhost.
+// Image overrides DefaultImage when set, and Registry configures how the image is resolved and pulled.
+// Alias is the network alias the container registers on the network, and defaults to DefaultAlias.
+type Zipkin struct {
+	Ports    map[int]nat.Port
+	Network  *testcontainers.DockerNetwork
+	Host     string
+	Image    string
+	Registry *registry.Config
+	Alias    string
+	Name     string
+}
+
+// Start starts the Zipkin container.
+func (z *Zipkin) Start(ctx context.Context) (func(context.Context) error, error) {
+	emptyFunc := func(context.Context) error { return nil }
+	var err error
+
+	z.Ports = make(map[int]nat.Port)
+
+	if z.Network == nil {
+		z.Network, err = network.New(ctx)
+		if err != nil {
+			return emptyFunc, fmt.Errorf("zipkin: network not provided and could not create a new one: %w", err)
+		}
+	}
+
+	if z.Alias == "" {
+		z.Alias = DefaultAlias
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:          z.image(),
+		ExposedPorts:   []string{"9411/tcp"},
+		Networks:       []string{z.Network.Name},
+		NetworkAliases: map[string][]string{z.Network.Name: {z.Alias}},
+		WaitingFor:     wait.ForHTTP("/health").WithPort("9411/tcp").WithStartupTimeout(time.Minute),
+	}
+	if err := z.Registry.Prepare(ctx, &req); err != nil {
+		return emptyFunc, fmt.Errorf("zipkin: could not prepare the image: %w", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return emptyFunc, fmt.Errorf("zipkin: could not start the testcontainer: %w", err)
+	}
+
+	z.Name, err = container.Name(ctx)
+	if err != nil {
+		return emptyFunc, fmt.Errorf("zipkin: could not read the name of the container from the testcontainer: %w", err)
+	}
+	z.Name = strings.TrimPrefix(z.Name, "/")
+
+	for _, portNum := range []int{9411} {
+		z.Ports[portNum], err = container.MappedPort(ctx, nat.Port(fmt.Sprintf("%d", portNum)))
+		if err != nil {
+			return emptyFunc, fmt.Errorf("zipkin: could not retrieve port %d from the testcontainer: %w", portNum, err)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return container.Terminate(ctx, testcontainers.StopTimeout(time.Second*30))
+	}, nil
+}
+
+// SpansEndpoint returns the URL that the collector's zipkin exporter sends spans to on the stack network.
+func (z *Zipkin) SpansEndpoint() string {
+	return fmt.Sprintf("http://%s:9411/api/v2/spans", z.Alias)
+}
+
+// Endpoint returns the URL that the given container port of Zipkin can be reached on.
+func (z *Zipkin) Endpoint(port int) string {
+	return fmt.Sprintf("http://%s:%d", z.host(), z.Ports[port].Int())
+}
+
+func (z *Zipkin) host() string {
+	if z.Host == "" {
+		return "localhost"
+	}
+	return z.Host
+}
+
+func (z *Zipkin) image() string {
+	if z.Image == "" {
+		return DefaultImage
+	}
+	return z.Image
+}
@@ -0,0 +1,30 @@
+package zipkin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZipkinStart(t *testing.T) {
+	t.Parallel()
+	z := Zipkin{}
+	shutdownFunc, err := z.Start(t.Context())
+	require.NoError(t, err, "zipkin must be able to start")
+	t.Cleanup(func() {
+		if err := shutdownFunc(context.Background()); err != nil {
+			t.Logf("error shutting down zipkin: %v", err)
+		}
+	})
+
+	endpoint := fmt.Sprintf("http://localhost:%d/health", z.Ports[9411].Int())
+	t.Logf("using endpoint: %s", endpoint)
+
+	resp, err := http.Get(endpoint)
+	require.NoError(t, err, "must be able to call zipkin")
+	assert.Equal(t, 200, resp.StatusCode, "request should be 200")
+}